@@ -49,9 +49,13 @@ func TestBankAccount(t *testing.T) {
 			},
 			{
 				Name: "withdraw",
-				Generator: gen.Map(gen.IntRange(1, 1000), func(amount int) BankCommand {
-					return BankCommand{Type: "withdraw", Amount: amount}
-				}),
+				// Amounts are drawn from the current balance, so withdrawals are
+				// generated whenever there is money in the account.
+				StateGenerator: func(state BankAccount) gen.Generator[BankCommand] {
+					return gen.Map(gen.IntRange(1, max(state.Balance, 1)), func(amount int) BankCommand {
+						return BankCommand{Type: "withdraw", Amount: amount}
+					})
+				},
 				Execute: func(state BankAccount, cmd BankCommand) (BankAccount, error) {
 					if state.Closed {
 						return state, errors.New("account is closed")
@@ -72,6 +76,7 @@ func TestBankAccount(t *testing.T) {
 			{
 				Name:      "close",
 				Generator: gen.Const(BankCommand{Type: "close", Amount: 0}),
+				Weight:    0.1, // closing ends the interesting part of a sequence
 				Execute: func(state BankAccount, cmd BankCommand) (BankAccount, error) {
 					return BankAccount{Balance: state.Balance, Closed: true}, nil
 				},
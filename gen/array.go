@@ -7,7 +7,8 @@ import "math/rand"
 // Shrink: cannot remove elements; only tries local shrink at each position,
 // exploring multiple branches (BFS/DFS) and deduplicating candidates.
 func ArrayOf[T any](elem Generator[T], n int) Generator[[]T] {
-	return From(func(r *rand.Rand, _ Size) ([]T, Shrinker[[]T]) {
+	return From(func(r *rand.Rand, sz Size) ([]T, Shrinker[[]T]) {
+		dfs := sz.DFS()
 		if r == nil {
 			// Using math/rand for deterministic property-based testing
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
@@ -20,7 +21,7 @@ func ArrayOf[T any](elem Generator[T], n int) Generator[[]T] {
 		cur := make([]T, n)
 		elS := make([]Shrinker[T], n)
 		for i := 0; i < n; i++ {
-			v, s := elem.Generate(r, Size{})
+			v, s := elem.Generate(r, sz.inner())
 			cur[i], elS[i] = v, s
 		}
 
@@ -59,7 +60,7 @@ func ArrayOf[T any](elem Generator[T], n int) Generator[[]T] {
 			if len(queue) == 0 {
 				return nil, false
 			}
-			if shrinkStrategy == ShrinkStrategyDFS {
+			if dfs {
 				v := queue[len(queue)-1]
 				queue = queue[:len(queue)-1]
 				return v, true
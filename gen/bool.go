@@ -5,7 +5,8 @@ import "math/rand"
 // Bool generates boolean values uniformly.
 // Shrink: prioritizes reducing to false (smaller counterexample by convention).
 func Bool() Generator[bool] {
-	return From(func(r *rand.Rand, _ Size) (bool, Shrinker[bool]) {
+	return From(func(r *rand.Rand, sz Size) (bool, Shrinker[bool]) {
+		dfs := sz.DFS()
 		if r == nil {
 			// Using math/rand for deterministic property-based testing
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
@@ -40,7 +41,7 @@ func Bool() Generator[bool] {
 			if len(queue) == 0 {
 				return false, false
 			}
-			if shrinkStrategy == ShrinkStrategyDFS {
+			if dfs {
 				v := queue[len(queue)-1]
 				queue = queue[:len(queue)-1]
 				return v, true
@@ -0,0 +1,425 @@
+package gen
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxChoices bounds how many choices a single draw may consume.
+// It protects replays of mutated sequences from running forever.
+const maxChoices = 8192
+
+// pairedShrinkWindow is how far after a lowered choice shrinking looks for a
+// span to delete in the same candidate.
+const pairedShrinkWindow = 8
+
+// maxRejects is how many rejected draws FromChoices tolerates while generating.
+const maxRejects = 1000
+
+// Choices is the recorded sequence of choices a choice-based generator draws from.
+//
+// While generating, choices come from the runner's random source and are recorded.
+// While shrinking, a mutated copy of the recorded sequence is replayed: choices
+// beyond its end are zero, which every primitive maps to its simplest value.
+// Because shrinking operates on the sequence rather than on values, anything
+// built on top of Choices (including Map/Bind/Filter compositions wrapped
+// with Choice) shrinks without per-type shrinker code.
+type Choices struct {
+	prefix []uint64
+	record []uint64
+	r      *rand.Rand
+	rr     *rand.Rand
+	size   Size
+}
+
+// rejectDraw is the panic value used by Reject to abandon the current draw.
+type rejectDraw struct{}
+
+// Size returns the size the generator was asked for.
+func (c *Choices) Size() Size { return c.size }
+
+// Uint64n draws a value in [0, n). Zero is the simplest choice.
+// If n is 0, the value spans the full uint64 range.
+func (c *Choices) Uint64n(n uint64) uint64 {
+	if len(c.record) >= maxChoices {
+		c.Reject()
+	}
+	var v uint64
+	if i := len(c.record); i < len(c.prefix) {
+		v = c.prefix[i]
+	} else if c.r != nil {
+		v = c.r.Uint64()
+	}
+	if n != 0 {
+		v %= n
+	}
+	c.record = append(c.record, v)
+	return v
+}
+
+// IntRange draws an integer in [min, max] (inclusive).
+// Smaller choices map to values closer to the shrink target (0 if it is in
+// range, otherwise the bound closest to 0), alternating above and below it.
+func (c *Choices) IntRange(min, max int) int {
+	if min > max {
+		min, max = max, min
+	}
+	t := shrinkTarget(min, max)
+	span := uint64(max) - uint64(min)
+	above := uint64(max) - uint64(t)
+	below := uint64(t) - uint64(min)
+	k := c.Uint64n(span + 1)
+
+	m := above
+	if below < m {
+		m = below
+	}
+	if k <= 2*m {
+		if k%2 == 1 {
+			return int(uint64(t) + (k+1)/2) // #nosec G115 -- Wraps back into [min, max]
+		}
+		return int(uint64(t) - k/2) // #nosec G115 -- Wraps back into [min, max]
+	}
+	rest := m + (k - 2*m)
+	if above > below {
+		return int(uint64(t) + rest) // #nosec G115 -- Wraps back into [min, max]
+	}
+	return int(uint64(t) - rest) // #nosec G115 -- Wraps back into [min, max]
+}
+
+// Bool draws a boolean; false is the simplest choice.
+func (c *Choices) Bool() bool { return c.Uint64n(2) == 1 }
+
+// Float64 draws a float in [0, 1); 0 is the simplest choice.
+func (c *Choices) Float64() float64 {
+	return float64(c.Uint64n(1<<53)) / (1 << 53)
+}
+
+// Reject abandons the current draw, e.g. when a value does not satisfy an
+// assumption. While generating, the draw is retried with fresh choices;
+// while shrinking, the candidate sequence is discarded.
+func (c *Choices) Reject() { panic(rejectDraw{}) }
+
+// rand returns a *rand.Rand whose randomness is taken from the choices, so
+// plain generators can be driven by the sequence too.
+func (c *Choices) rand() *rand.Rand {
+	if c.rr == nil {
+		c.rr = rand.New(choiceSource{c: c}) // #nosec G404 -- Using math/rand for deterministic property-based testing
+		choiceRands.Store(c.rr, c)
+	}
+	return c.rr
+}
+
+// release unregisters the *rand.Rand handed out by rand.
+func (c *Choices) release() {
+	if c.rr != nil {
+		choiceRands.Delete(c.rr)
+	}
+}
+
+// choiceRands maps the *rand.Rand values handed out by Choices.rand back to
+// their Choices, so choice-based generators reached through plain combinators
+// (Map, Bind, Filter, ...) keep drawing from the same sequence.
+var choiceRands sync.Map
+
+// choicesOf returns the Choices backing r, or nil if r is an ordinary *rand.Rand.
+func choicesOf(r *rand.Rand) *Choices {
+	if r == nil {
+		return nil
+	}
+	if c, ok := choiceRands.Load(r); ok {
+		return c.(*Choices)
+	}
+	return nil
+}
+
+// choiceSource adapts Choices to rand.Source64: every value read by a plain
+// generator becomes one choice in the sequence.
+type choiceSource struct {
+	c *Choices
+}
+
+// Int63 implements rand.Source. The value is drawn as two choices, the high
+// 31 bits first: rand.Intn and friends only read those bits for small ranges,
+// so lowering that choice lowers the value they return.
+func (s choiceSource) Int63() int64 {
+	hi := s.c.Uint64n(1 << 31)
+	lo := s.c.Uint64n(1 << 32)
+	return int64(hi<<32 | lo) // #nosec G115 -- Value is below 1<<63
+}
+
+// Uint64 implements rand.Source64.
+func (s choiceSource) Uint64() uint64 { return s.c.Uint64n(0) }
+
+// Seed implements rand.Source; choices cannot be reseeded.
+func (s choiceSource) Seed(int64) {}
+
+// choiceDrawer is implemented by generators that can draw directly from Choices.
+type choiceDrawer[T any] interface {
+	drawChoices(c *Choices) T
+}
+
+// choiceGen is a Generator backed by a draw function over Choices.
+type choiceGen[T any] struct {
+	draw func(*Choices) T
+}
+
+// FromChoices creates a Generator from a function that draws its value from Choices.
+// Shrinking is done on the recorded choice sequence (deleting spans, zeroing
+// blocks and lowering single choices), so no shrinker has to be written.
+//
+// Example:
+//
+//	point := gen.FromChoices(func(c *gen.Choices) Point {
+//	    return Point{X: c.IntRange(-10, 10), Y: c.IntRange(-10, 10)}
+//	})
+func FromChoices[T any](draw func(*Choices) T) Generator[T] {
+	return choiceGen[T]{draw: draw}
+}
+
+// Choice runs any Generator on the choice-sequence engine.
+// All randomness consumed by g, including by the generators it is composed of
+// through Map, Bind or Filter, is recorded and shrunk as a single sequence,
+// instead of relying on each generator's own shrinker.
+func Choice[T any](g Generator[T]) Generator[T] {
+	return FromChoices(func(c *Choices) T { return Draw(c, g) })
+}
+
+// Draw draws a value of g from Choices. It is the way to use other generators
+// inside a FromChoices function. Generators that are not choice-based are
+// driven by a random source reading from the choices.
+func Draw[T any](c *Choices, g Generator[T]) T {
+	if d, ok := g.(choiceDrawer[T]); ok {
+		return d.drawChoices(c)
+	}
+	v, _ := g.Generate(c.rand(), c.size)
+	return v
+}
+
+// DrawSlice draws a slice with length in [min, max] from elem.
+// Each element is preceded by a "continue" choice, so deleting a span of the
+// sequence removes whole elements and zeroing a choice ends the slice there.
+func DrawSlice[T any](c *Choices, elem Generator[T], min, max int) []T {
+	if max < min {
+		max = min
+	}
+	avg := (min + max) / 2
+	if avg < 1 {
+		avg = 1
+	}
+	out := make([]T, 0, min)
+	for len(out) < max {
+		if len(out) >= min && c.Uint64n(uint64(avg)+1) == 0 { // #nosec G115 -- avg is positive
+			break
+		}
+		out = append(out, Draw(c, elem))
+	}
+	return out
+}
+
+// drawChoices implements choiceDrawer.
+func (g choiceGen[T]) drawChoices(c *Choices) T { return g.draw(c) }
+
+// Generate implements the Generator interface for choice-based generators.
+func (g choiceGen[T]) Generate(r *rand.Rand, sz Size) (T, Shrinker[T]) {
+	if c := choicesOf(r); c != nil {
+		// nested in another choice-based draw: the outer sequence owns shrinking
+		return g.draw(c), func(bool) (T, bool) { var z T; return z, false }
+	}
+	if r == nil {
+		r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
+	}
+	for tries := 0; tries < maxRejects; tries++ {
+		if v, seq, ok := runChoices(g.draw, &Choices{r: r, size: sz}); ok {
+			return choiceShrinkInit(g.draw, sz, v, seq)
+		}
+	}
+	panic("gen.FromChoices: too many rejected draws")
+}
+
+// runChoices runs draw over c and reports the value, the choices it consumed,
+// and whether the draw completed (false if it was rejected).
+func runChoices[T any](draw func(*Choices) T, c *Choices) (v T, seq []uint64, ok bool) {
+	defer c.release()
+	defer func() {
+		if rec := recover(); rec != nil {
+			if _, rejected := rec.(rejectDraw); !rejected {
+				panic(rec)
+			}
+			ok = false
+		}
+	}()
+	v = draw(c)
+	return v, c.record, true
+}
+
+// -------------------- implementation / shrinking --------------------
+
+// choiceShrinkInit initializes the shrinking process for a value drawn from a
+// choice sequence. Candidates are mutations of the sequence which are replayed
+// through draw; only replays that consume a shortlex-smaller sequence are proposed.
+func choiceShrinkInit[T any](draw func(*Choices) T, sz Size, start T, seq []uint64) (T, Shrinker[T]) {
+	dfs := sz.DFS()
+	cur := seq
+	var last []uint64
+
+	// seen holds the sequences already replayed; queued the ones waiting in
+	// the current round (a rebase drops the round, so they may be queued again)
+	queue := make([][]uint64, 0, 64)
+	seen := map[string]struct{}{seqKey(cur): {}}
+	queued := map[string]struct{}{}
+
+	push := func(s []uint64) {
+		k := seqKey(s)
+		if _, ok := seen[k]; ok {
+			return
+		}
+		if _, ok := queued[k]; ok {
+			return
+		}
+		queued[k] = struct{}{}
+		queue = append(queue, s)
+	}
+
+	// remove the span [i:j) of base
+	del := func(base []uint64, i, j int) []uint64 {
+		out := make([]uint64, 0, len(base)-(j-i))
+		out = append(out, base[:i]...)
+		return append(out, base[j:]...)
+	}
+
+	// zero the span [i:j) of base
+	zero := func(base []uint64, i, j int) []uint64 {
+		out := append(([]uint64)(nil), base...)
+		for k := i; k < j; k++ {
+			out[k] = 0
+		}
+		return out
+	}
+
+	// replace base[i] by v
+	set := func(base []uint64, i int, v uint64) []uint64 {
+		out := append(([]uint64)(nil), base...)
+		out[i] = v
+		return out
+	}
+
+	// heuristics, from the most to the least aggressive:
+	//  1) delete spans (half, quarter, ..., and every short span of 1 or 2 choices)
+	//  2) zero spans
+	//  3) lower single choices (to 0, then bisecting back up to minus one)
+	//  4) lower a choice and delete a later span together
+	growNeighbors := func(base []uint64) {
+		queue = queue[:0]
+		clear(queued)
+		L := len(base)
+		for chunk := L / 2; chunk >= 1; chunk /= 2 {
+			for i := 0; i+chunk <= L; i += chunk {
+				push(del(base, i, i+chunk))
+			}
+		}
+		for _, chunk := range []int{2, 1} {
+			for i := 0; i+chunk <= L; i++ {
+				push(del(base, i, i+chunk))
+			}
+		}
+		for chunk := L / 2; chunk >= 2; chunk /= 2 {
+			for i := 0; i+chunk <= L; i += chunk {
+				push(zero(base, i, i+chunk))
+			}
+		}
+		for i := 0; i < L; i++ {
+			v := base[i]
+			if v == 0 {
+				continue
+			}
+			// 0, then halfway, three quarters, ... of the way back up to v-1
+			push(set(base, i, 0))
+			for d := v / 2; d > 0; d /= 2 {
+				push(set(base, i, v-d))
+			}
+		}
+		// (4) lower a choice by one and delete a later span: shortens a
+		//     collection whose length was drawn before its elements
+		for i := 0; i < L; i++ {
+			if base[i] == 0 {
+				continue
+			}
+			lowered := set(base, i, base[i]-1)
+			for _, chunk := range []int{1, 2} {
+				for j := i + 1; j+chunk <= L && j <= i+pairedShrinkWindow; j++ {
+					push(del(lowered, j, j+chunk))
+				}
+			}
+		}
+	}
+	growNeighbors(cur)
+
+	pop := func() ([]uint64, bool) {
+		if len(queue) == 0 {
+			return nil, false
+		}
+		if dfs {
+			v := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			return v, true
+		}
+		v := queue[0]
+		queue = queue[1:]
+		return v, true
+	}
+
+	return start, func(accept bool) (T, bool) {
+		if accept && last != nil {
+			// rebase on the sequence consumed by the accepted candidate
+			cur = last
+			growNeighbors(cur)
+		}
+		for {
+			cand, ok := pop()
+			if !ok {
+				var z T
+				return z, false
+			}
+			seen[seqKey(cand)] = struct{}{}
+			v, used, ok := runChoices(draw, &Choices{prefix: cand, size: sz})
+			if !ok || !shortlexLess(used, cur) {
+				continue
+			}
+			if k := seqKey(used); k != seqKey(cand) {
+				if _, dup := seen[k]; dup {
+					continue
+				}
+				seen[k] = struct{}{}
+			}
+			last = append(([]uint64)(nil), used...)
+			return v, true
+		}
+	}
+}
+
+// shortlexLess reports whether a is simpler than b: shorter, or of equal
+// length and lexicographically smaller.
+func shortlexLess(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// seqKey creates a textual key of a choice sequence for deduplication.
+func seqKey(s []uint64) string {
+	var b strings.Builder
+	for _, v := range s {
+		b.WriteString(strconv.FormatUint(v, 36))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
@@ -0,0 +1,253 @@
+package gen
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// shrinkWhile drives a shrinker like the runner does, accepting every
+// candidate for which fails returns true, and returns the minimal value.
+func shrinkWhile[T any](v T, s Shrinker[T], fails func(T) bool, maxSteps int) T {
+	min := v
+	accept := true
+	for i := 0; i < maxSteps; i++ {
+		next, ok := s(accept)
+		if !ok {
+			break
+		}
+		accept = fails(next)
+		if accept {
+			min = next
+		}
+	}
+	return min
+}
+
+func TestChoices_IntRangeBounds(t *testing.T) {
+	tests := []struct {
+		min, max int
+	}{
+		{-10, 10},
+		{5, 20},
+		{-20, -5},
+		{0, 0},
+		{3, -3},
+	}
+
+	r := rand.New(rand.NewSource(123))
+	for _, tt := range tests {
+		c := &Choices{r: r}
+		lo, hi := tt.min, tt.max
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for i := 0; i < 200; i++ {
+			v := c.IntRange(tt.min, tt.max)
+			if v < lo || v > hi {
+				t.Fatalf("IntRange(%d, %d) = %d, out of range", tt.min, tt.max, v)
+			}
+		}
+	}
+}
+
+func TestChoices_IntRangeOrdering(t *testing.T) {
+	tests := []struct {
+		min, max int
+		want     []int
+	}{
+		{-2, 5, []int{0, 1, -1, 2, -2, 3, 4, 5}},
+		{5, 8, []int{5, 6, 7, 8}},
+		{-8, -5, []int{-5, -6, -7, -8}},
+	}
+
+	for _, tt := range tests {
+		for k, want := range tt.want {
+			c := &Choices{prefix: []uint64{uint64(k)}}
+			if got := c.IntRange(tt.min, tt.max); got != want {
+				t.Errorf("IntRange(%d, %d) with choice %d = %d, expected %d", tt.min, tt.max, k, got, want)
+			}
+		}
+	}
+}
+
+func TestChoices_ReplayBeyondPrefixIsZero(t *testing.T) {
+	c := &Choices{prefix: []uint64{7}}
+
+	if got := c.Uint64n(10); got != 7 {
+		t.Errorf("Uint64n() = %d, expected replayed 7", got)
+	}
+	if got := c.Uint64n(10); got != 0 {
+		t.Errorf("Uint64n() beyond prefix = %d, expected 0", got)
+	}
+	if c.Bool() {
+		t.Error("Bool() beyond prefix should be false")
+	}
+	if len(c.record) != 3 {
+		t.Errorf("Expected 3 recorded choices, got %d", len(c.record))
+	}
+}
+
+func TestFromChoices_Deterministic(t *testing.T) {
+	g := FromChoices(func(c *Choices) []int {
+		return DrawSlice(c, FromChoices(func(c *Choices) int { return c.IntRange(-100, 100) }), 0, 10)
+	})
+
+	v1, _ := g.Generate(rand.New(rand.NewSource(42)), Size{})
+	v2, _ := g.Generate(rand.New(rand.NewSource(42)), Size{})
+	if sig(v1) != sig(v2) {
+		t.Errorf("Expected same value for same seed, got %v and %v", v1, v2)
+	}
+}
+
+func TestFromChoices_ShrinksComposite(t *testing.T) {
+	type pair struct{ a, b int }
+	g := FromChoices(func(c *Choices) pair {
+		return pair{a: c.IntRange(0, 1000), b: c.IntRange(0, 1000)}
+	})
+	fails := func(p pair) bool { return p.a+p.b > 100 }
+
+	r := rand.New(rand.NewSource(7))
+	for i := 0; i < 20; i++ {
+		v, s := g.Generate(r, Size{})
+		if !fails(v) {
+			continue
+		}
+		min := shrinkWhile(v, s, fails, 2000)
+		if min.a+min.b != 101 {
+			t.Errorf("Expected minimal sum 101, got %+v (from %+v)", min, v)
+		}
+	}
+}
+
+func TestDrawSlice_ShrinksByDeletingElements(t *testing.T) {
+	elem := FromChoices(func(c *Choices) int { return c.IntRange(0, 100) })
+	g := FromChoices(func(c *Choices) []int { return DrawSlice(c, elem, 0, 20) })
+	fails := func(xs []int) bool {
+		for _, x := range xs {
+			if x >= 50 {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := rand.New(rand.NewSource(11))
+	found := false
+	for i := 0; i < 50; i++ {
+		v, s := g.Generate(r, Size{})
+		if !fails(v) {
+			continue
+		}
+		found = true
+		min := shrinkWhile(v, s, fails, 2000)
+		if len(min) != 1 || min[0] != 50 {
+			t.Errorf("Expected [50], got %v (from %v)", min, v)
+		}
+	}
+	if !found {
+		t.Fatal("Expected at least one failing value")
+	}
+}
+
+func TestDrawSlice_RespectsBounds(t *testing.T) {
+	g := FromChoices(func(c *Choices) []bool { return DrawSlice(c, Bool(), 2, 5) })
+
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 100; i++ {
+		v, _ := g.Generate(r, Size{})
+		if len(v) < 2 || len(v) > 5 {
+			t.Fatalf("DrawSlice() length = %d, expected [2, 5]", len(v))
+		}
+	}
+}
+
+func TestChoice_ShrinksBindComposition(t *testing.T) {
+	// Bind regenerates B when A shrinks; on the choice engine both shrink together.
+	elem := FromChoices(func(c *Choices) int { return c.IntRange(0, 100) })
+	g := Choice(Bind(FromChoices(func(c *Choices) int { return c.IntRange(0, 10) }), func(n int) Generator[[]int] {
+		return ArrayOf(elem, n)
+	}))
+	fails := func(xs []int) bool {
+		for _, x := range xs {
+			if x >= 50 {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := rand.New(rand.NewSource(5))
+	found := false
+	for i := 0; i < 50; i++ {
+		v, s := g.Generate(r, Size{})
+		if !fails(v) {
+			continue
+		}
+		found = true
+		min := shrinkWhile(v, s, fails, 5000)
+		if len(min) != 1 || min[0] != 50 {
+			t.Errorf("Expected [50], got %v (from %v)", min, v)
+		}
+	}
+	if !found {
+		t.Fatal("Expected at least one failing value")
+	}
+}
+
+func TestChoice_PlainGenerators(t *testing.T) {
+	g := Choice(Map(SliceOf(IntRange(0, 1000), Size{Max: 10}), func(xs []int) int { return len(xs) }))
+	fails := func(n int) bool { return n >= 3 }
+
+	r := rand.New(rand.NewSource(9))
+	for i := 0; i < 20; i++ {
+		v, s := g.Generate(r, Size{})
+		if !fails(v) {
+			continue
+		}
+		min := shrinkWhile(v, s, fails, 2000)
+		if min != 3 {
+			t.Errorf("Expected length 3, got %d (from %d)", min, v)
+		}
+	}
+}
+
+func TestChoice_FilterRejectsInsteadOfZero(t *testing.T) {
+	elem := FromChoices(func(c *Choices) int { return c.IntRange(0, 100) })
+	g := Choice(Filter(elem, func(x int) bool { return x > 10 }, 5))
+	fails := func(x int) bool { return x > 10 }
+
+	r := rand.New(rand.NewSource(13))
+	for i := 0; i < 20; i++ {
+		v, s := g.Generate(r, Size{})
+		if v <= 10 {
+			t.Fatalf("Filter() produced %d, expected > 10", v)
+		}
+		min := shrinkWhile(v, s, func(x int) bool {
+			if x <= 10 {
+				t.Fatalf("shrinker proposed %d, which fails the filter", x)
+			}
+			return fails(x)
+		}, 2000)
+		if min != 11 {
+			t.Errorf("Expected 11, got %d", min)
+		}
+	}
+}
+
+func TestShortlexLess(t *testing.T) {
+	tests := []struct {
+		a, b []uint64
+		want bool
+	}{
+		{[]uint64{1}, []uint64{0, 0}, true},
+		{[]uint64{0, 1}, []uint64{0, 2}, true},
+		{[]uint64{0, 2}, []uint64{0, 2}, false},
+		{[]uint64{3}, []uint64{2}, false},
+	}
+
+	for _, tt := range tests {
+		if got := shortlexLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("shortlexLess(%v, %v) = %v, expected %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
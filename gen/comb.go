@@ -124,6 +124,10 @@ func Filter[T any](g Generator[T], pred func(T) bool, maxTries int) Generator[T]
 			}
 		}
 		if !okv {
+			// on the choice-sequence engine, discard the draw instead of yielding a zero value
+			if c := choicesOf(r); c != nil {
+				c.Reject()
+			}
 			var z T
 			return z, func(bool) (T, bool) { return z, false }
 		}
@@ -188,3 +192,145 @@ func Bind[A, B any](ga Generator[A], f func(A) Generator[B]) Generator[B] {
 		}
 	})
 }
+
+// -------------------------
+// Shrinking helpers
+// -------------------------
+
+// shrinkInTurn shrinks a value made of independent components, one component
+// at a time: component i is shrunk with shks[i] (propagating accept) while the
+// others hold their current values; when it is exhausted, the next one starts.
+// build assembles a candidate from the component values.
+func shrinkInTurn[T, V any](cur []V, shks []Shrinker[V], build func([]V) T) Shrinker[T] {
+	return shrinkInTurnWhere(cur, shks, nil, build)
+}
+
+// shrinkInTurnWhere is shrinkInTurn restricted to component candidates
+// accepted by valid, which sees the current components and the index being
+// shrunk. Invalid candidates are skipped as if they had been rejected.
+func shrinkInTurnWhere[T, V any](cur []V, shks []Shrinker[V], valid func(cur []V, i int, v V) bool, build func([]V) T) Shrinker[T] {
+	cur = append(([]V)(nil), cur...)
+	idx := 0
+	var pending V
+	proposed := false
+
+	return func(accept bool) (T, bool) {
+		// the accepted candidate becomes the current value of its component
+		if accept && proposed {
+			cur[idx] = pending
+		}
+		for idx < len(shks) {
+			for shks[idx] != nil {
+				nv, ok := shks[idx](accept)
+				if !ok {
+					break
+				}
+				accept = false
+				if valid != nil && !valid(cur, idx, nv) {
+					continue
+				}
+				pending, proposed = nv, true
+				cand := append(([]V)(nil), cur...)
+				cand[idx] = nv
+				return build(cand), true
+			}
+			// component exhausted → move to the next one, starting fresh
+			idx++
+			accept, proposed = false, false
+		}
+		var z T
+		return z, false
+	}
+}
+
+// shrinkByRemoval shrinks a collection of n elements by removing them: first
+// in large blocks (half, quarter, ...), then one at a time (right→left), never
+// keeping fewer than min. Candidates are the indices of the kept elements,
+// turned into values by build. Once no removal is left to try, the shrinker
+// returned by then, given the indices that survived, takes over. Candidates
+// are popped depth-first when dfs is set.
+func shrinkByRemoval[T any](n, min int, dfs bool, build func(idx []int) T, then func(idx []int) Shrinker[T]) Shrinker[T] {
+	cur := make([]int, n)
+	for i := range cur {
+		cur[i] = i
+	}
+	seen := map[string]struct{}{sig(cur): {}}
+	queue := make([][]int, 0, 64)
+	var last []int
+
+	push := func(idx []int) {
+		if len(idx) < min {
+			return
+		}
+		k := sig(idx)
+		if _, ok := seen[k]; ok {
+			return
+		}
+		seen[k] = struct{}{}
+		queue = append(queue, idx)
+	}
+
+	rem := func(base []int, i, j int) []int {
+		out := make([]int, 0, len(base)-(j-i))
+		out = append(out, base[:i]...)
+		out = append(out, base[j:]...)
+		return out
+	}
+
+	growNeighbors := func(base []int) {
+		queue = queue[:0]
+		L := len(base)
+		// (1) remove large blocks (binary: half, quarter, ...)
+		for chunk := L / 2; chunk >= 1; chunk /= 2 {
+			for i := 0; i+chunk <= L; i += chunk {
+				push(rem(base, i, i+chunk))
+			}
+		}
+		// (2) remove isolated element (R->L)
+		for i := L - 1; i >= 0; i-- {
+			push(rem(base, i, i+1))
+		}
+	}
+	growNeighbors(cur)
+
+	pop := func() ([]int, bool) {
+		if len(queue) == 0 {
+			return nil, false
+		}
+		if dfs {
+			v := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			return v, true
+		}
+		v := queue[0]
+		queue = queue[1:]
+		return v, true
+	}
+
+	var next Shrinker[T]
+	return func(accept bool) (T, bool) {
+		if next == nil {
+			if accept && last != nil {
+				cur = last
+				growNeighbors(cur)
+			}
+			if nxt, ok := pop(); ok {
+				last = nxt
+				return build(nxt), true
+			}
+			// the last removal was either accepted (rebased above) or rejected
+			accept = false
+			next = then(cur)
+		}
+		return next(accept)
+	}
+}
+
+// pickValues returns the elements of s at the given indices.
+func pickValues[T any](s []T, idx []int) []T {
+	out := make([]T, len(idx))
+	for j, i := range idx {
+		out[j] = s[i]
+	}
+	return out
+}
@@ -11,7 +11,7 @@ import (
 
 // CPF generates valid CPF numbers; masked controls the format.
 func CPF(masked bool) gen.Generator[string] {
-	return gen.From(func(r *rand.Rand, _ gen.Size) (string, gen.Shrinker[string]) {
+	return gen.From(func(r *rand.Rand, sz gen.Size) (string, gen.Shrinker[string]) {
 		if r == nil {
 			// Using math/rand for deterministic property-based testing
 			// This is appropriate for test data generation, not cryptographic purposes
@@ -19,7 +19,7 @@ func CPF(masked bool) gen.Generator[string] {
 		}
 
 		cur := generateCPF(r, masked)
-		shrink := createCPFShrinker(cur)
+		shrink := createCPFShrinker(cur, sz.DFS())
 		return cur, shrink
 	})
 }
@@ -51,8 +51,9 @@ func generateCPF(r *rand.Rand, masked bool) string {
 	return cur
 }
 
-// createCPFShrinker creates a shrinker for CPF values
-func createCPFShrinker(initial string) gen.Shrinker[string] {
+// createCPFShrinker creates a shrinker for CPF values, popping candidates
+// depth-first if dfs is set
+func createCPFShrinker(initial string, dfs bool) gen.Shrinker[string] {
 	queue := make([]string, 0, 32)
 	seen := make(map[string]struct{}, 64) // dedup
 	var last string                       // last proposed
@@ -86,7 +87,7 @@ func createCPFShrinker(initial string) gen.Shrinker[string] {
 		if len(queue) == 0 {
 			return "", false
 		}
-		if gen.GetShrinkStrategy() == gen.ShrinkStrategyDFS {
+		if dfs {
 			// LIFO
 			v := queue[len(queue)-1]
 			queue = queue[:len(queue)-1]
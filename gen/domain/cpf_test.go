@@ -23,6 +23,35 @@ func TestCPF(t *testing.T) {
 	}
 }
 
+// TestCPF_ShrinkStrategy tests that the CPF shrinker follows the strategy of
+// the Size it is generated with, not the default strategy: rejecting every
+// candidate, DFS proposes the neighbours of the value in reverse BFS order.
+func TestCPF_ShrinkStrategy(t *testing.T) {
+	gen.SetShrinkStrategy(gen.ShrinkStrategyBFS)
+	candidates := func(strategy string) []string {
+		_, shrink := CPF(false).Generate(rand.New(rand.NewSource(123)), gen.Size{Strategy: strategy})
+		var out []string
+		for {
+			v, ok := shrink(false)
+			if !ok {
+				return out
+			}
+			out = append(out, v)
+		}
+	}
+
+	bfs := candidates(gen.ShrinkStrategyBFS)
+	dfs := candidates(gen.ShrinkStrategyDFS)
+	if len(bfs) < 2 || len(dfs) != len(bfs) {
+		t.Fatalf("Expected the same neighbours with both strategies, got %d and %d", len(bfs), len(dfs))
+	}
+	for i := range dfs {
+		if dfs[i] != bfs[len(bfs)-1-i] {
+			t.Fatalf("Expected DFS candidates %v to be BFS candidates %v reversed", dfs, bfs)
+		}
+	}
+}
+
 func TestCPFAny(t *testing.T) {
 	cpf := CPFAny()
 	r := rand.New(rand.NewSource(123))
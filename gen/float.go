@@ -17,7 +17,7 @@ func Float32(size Size) Generator[float32] {
 			min, max = max, min
 		}
 		v := uniformF32(r, min, max)
-		return float32ShrinkInit(v, min, max, false, false)
+		return float32ShrinkInit(v, min, max, false, false, sz.DFS())
 	})
 }
 
@@ -26,7 +26,7 @@ func Float32Range(min, max float32, includeNaN, includeInf bool) Generator[float
 	if min > max {
 		min, max = max, min
 	}
-	return From(func(r *rand.Rand, _ Size) (float32, Shrinker[float32]) {
+	return From(func(r *rand.Rand, sz Size) (float32, Shrinker[float32]) {
 		if r == nil {
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
 		}
@@ -40,7 +40,7 @@ func Float32Range(min, max float32, includeNaN, includeInf bool) Generator[float
 				v = float32(math.Inf(-1))
 			}
 		}
-		return float32ShrinkInit(v, min, max, includeNaN, includeInf)
+		return float32ShrinkInit(v, min, max, includeNaN, includeInf, sz.DFS())
 	})
 }
 
@@ -49,7 +49,7 @@ func Float32Range(min, max float32, includeNaN, includeInf bool) Generator[float
 // float32ShrinkInit initializes the shrinking process for a float32 value.
 // It returns the initial value and a shrinker function that can generate
 // progressively smaller candidates.
-func float32ShrinkInit(start, min, max float32, allowNaN, allowInf bool) (float32, Shrinker[float32]) {
+func float32ShrinkInit(start, min, max float32, allowNaN, allowInf, dfs bool) (float32, Shrinker[float32]) {
 	cur := clampF32(start, min, max)
 	last := cur
 
@@ -152,7 +152,7 @@ func float32ShrinkInit(start, min, max float32, allowNaN, allowInf bool) (float3
 		if len(queue) == 0 {
 			return 0, false
 		}
-		if shrinkStrategy == ShrinkStrategyDFS {
+		if dfs {
 			v := queue[len(queue)-1]
 			queue = queue[:len(queue)-1]
 			return v, true
@@ -206,7 +206,7 @@ func clampF32(x, min, max float32) float32 {
 
 // autoRangeF32 decides the final range for Float32(...) by combining the local "size" and the
 // "size" coming from the runner. We prefer the largest range informed; if nothing is
-// informed, we use [-100, 100].
+// informed, we use the runner's Scale, or [-100, 100] without one.
 func autoRangeF32(local, fromRunner Size) (float32, float32) {
 	M := 0
 	for _, s := range []Size{local, fromRunner} {
@@ -218,7 +218,7 @@ func autoRangeF32(local, fromRunner Size) (float32, float32) {
 		}
 	}
 	if M == 0 {
-		M = 100
+		M = fromRunner.scaleOr(100)
 	}
 	return -float32(M), float32(M)
 }
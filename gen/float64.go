@@ -18,13 +18,13 @@ func Float64(size Size) Generator[float64] {
 			min, max = max, min
 		}
 		v := uniformF64(r, min, max)
-		return float64ShrinkInit(v, min, max, false, false)
+		return float64ShrinkInit(v, min, max, false, false, sz.DFS())
 	})
 }
 
 // autoRangeF64 decides the final range for Float64(...) by combining the local "size" and the
 // "size" coming from the runner. We prefer the largest range informed; if nothing is
-// informed, we use [-100, 100].
+// informed, we use the runner's Scale, or [-100, 100] without one.
 func autoRangeF64(local, fromRunner Size) (float64, float64) {
 	M := 0
 	for _, s := range []Size{local, fromRunner} {
@@ -36,7 +36,7 @@ func autoRangeF64(local, fromRunner Size) (float64, float64) {
 		}
 	}
 	if M == 0 {
-		M = 100
+		M = fromRunner.scaleOr(100)
 	}
 	return -float64(M), float64(M)
 }
@@ -47,7 +47,7 @@ func Float64Range(min, max float64, includeNaN, includeInf bool) Generator[float
 	if min > max {
 		min, max = max, min
 	}
-	return From(func(r *rand.Rand, _ Size) (float64, Shrinker[float64]) {
+	return From(func(r *rand.Rand, sz Size) (float64, Shrinker[float64]) {
 		if r == nil {
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
 		}
@@ -62,7 +62,7 @@ func Float64Range(min, max float64, includeNaN, includeInf bool) Generator[float
 				v = math.Inf(-1)
 			}
 		}
-		return float64ShrinkInit(v, min, max, includeNaN, includeInf)
+		return float64ShrinkInit(v, min, max, includeNaN, includeInf, sz.DFS())
 	})
 }
 
@@ -71,7 +71,7 @@ func Float64Range(min, max float64, includeNaN, includeInf bool) Generator[float
 // float64ShrinkInit initializes the shrinking process for a float64 value.
 // It returns the initial value and a shrinker function that can generate
 // progressively smaller candidates.
-func float64ShrinkInit(start, min, max float64, allowNaN, allowInf bool) (float64, Shrinker[float64]) {
+func float64ShrinkInit(start, min, max float64, allowNaN, allowInf, dfs bool) (float64, Shrinker[float64]) {
 	cur := clampF64(start, min, max) // NaN stays as NaN; clamp doesn't alter NaN
 	last := cur
 
@@ -184,7 +184,7 @@ func float64ShrinkInit(start, min, max float64, allowNaN, allowInf bool) (float6
 		if len(queue) == 0 {
 			return 0, false
 		}
-		if shrinkStrategy == ShrinkStrategyDFS {
+		if dfs {
 			v := queue[len(queue)-1]
 			queue = queue[:len(queue)-1]
 			return v, true
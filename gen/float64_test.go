@@ -7,7 +7,7 @@ import (
 )
 
 func TestFloat64ShrinkerWithAccept(t *testing.T) {
-	_, shrink := float64ShrinkInit(50.0, 0.0, 100.0, false, false)
+	_, shrink := float64ShrinkInit(50.0, 0.0, 100.0, false, false, false)
 
 	next1, ok1 := shrink(false)
 	if !ok1 {
@@ -26,7 +26,7 @@ func TestFloat64ShrinkerWithAccept(t *testing.T) {
 
 func TestFloat64ShrinkerExhaustion(t *testing.T) {
 	// Test shrinking behavior until exhaustion
-	_, shrink := float64ShrinkInit(50.0, 0.0, 100.0, false, false)
+	_, shrink := float64ShrinkInit(50.0, 0.0, 100.0, false, false, false)
 
 	callCount := 0
 	for {
@@ -50,7 +50,7 @@ func TestFloat64ShrinkerWithDFSSStrategy(t *testing.T) {
 	SetShrinkStrategy(ShrinkStrategyDFS)
 	defer SetShrinkStrategy(ShrinkStrategyBFS)
 
-	_, shrink := float64ShrinkInit(50.0, 0.0, 100.0, false, false)
+	_, shrink := float64ShrinkInit(50.0, 0.0, 100.0, false, false, true)
 
 	next, ok := shrink(false)
 	if !ok {
@@ -82,7 +82,7 @@ func TestFloat64ShrinkerEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			start, shrink := float64ShrinkInit(tt.start, tt.min, tt.max, tt.allowNaN, tt.allowInf)
+			start, shrink := float64ShrinkInit(tt.start, tt.min, tt.max, tt.allowNaN, tt.allowInf, false)
 
 			if !math.IsNaN(tt.start) && start != tt.start {
 				t.Errorf("float64ShrinkInit() start = %f, expected %f", start, tt.start)
@@ -42,7 +42,7 @@ func TestFloat32Range(t *testing.T) {
 
 func TestFloat32Shrinker(t *testing.T) {
 	// Test float32 shrinking behavior
-	start, shrink := float32ShrinkInit(50.0, 0.0, 100.0, false, false)
+	start, shrink := float32ShrinkInit(50.0, 0.0, 100.0, false, false, false)
 
 	if start != 50.0 {
 		t.Errorf("float32ShrinkInit() start = %f, expected 50.0", start)
@@ -224,7 +224,7 @@ func TestFloat64Range(t *testing.T) {
 
 func TestFloat64Shrinker(t *testing.T) {
 	// Test float64 shrinking behavior
-	start, shrink := float64ShrinkInit(50.0, 0.0, 100.0, false, false)
+	start, shrink := float64ShrinkInit(50.0, 0.0, 100.0, false, false, false)
 
 	if start != 50.0 {
 		t.Errorf("float64ShrinkInit() start = %f, expected 50.0", start)
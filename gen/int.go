@@ -20,7 +20,7 @@ func Int(size Size) Generator[int] {
 		}
 		// generate uniformly
 		v := min + r.Intn(max-min+1)
-		return intShrinkInit(v, min, max)
+		return intShrinkInit(v, min, max, sz.DFS())
 	})
 }
 
@@ -30,12 +30,12 @@ func IntRange(min, max int) Generator[int] {
 	if min > max {
 		min, max = max, min
 	}
-	return From(func(r *rand.Rand, _ Size) (int, Shrinker[int]) {
+	return From(func(r *rand.Rand, sz Size) (int, Shrinker[int]) {
 		if r == nil {
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
 		}
 		v := min + r.Intn(max-min+1)
-		return intShrinkInit(v, min, max)
+		return intShrinkInit(v, min, max, sz.DFS())
 	})
 }
 
@@ -44,7 +44,7 @@ func IntRange(min, max int) Generator[int] {
 // intShrinkInit initializes the shrinking process for an integer value.
 // It returns the initial value and a shrinker function that can generate
 // progressively smaller candidates.
-func intShrinkInit(start, min, max int) (int, Shrinker[int]) {
+func intShrinkInit(start, min, max int, dfs bool) (int, Shrinker[int]) {
 	// current value (minimum known that fails) and last proposed
 	cur := clamp(start, min, max)
 	last := cur
@@ -121,7 +121,7 @@ func intShrinkInit(start, min, max int) (int, Shrinker[int]) {
 		if len(queue) == 0 {
 			return 0, false
 		}
-		if shrinkStrategy == ShrinkStrategyDFS {
+		if dfs {
 			v := queue[len(queue)-1]
 			queue = queue[:len(queue)-1]
 			return v, true
@@ -196,7 +196,7 @@ func stepTowards(a, b int) int {
 
 // autoRange decides the final range for Int(...) by combining the local "size" and the
 // "size" coming from the runner. We prefer the largest range informed; if nothing is
-// informed, we use [-100, 100].
+// informed, we use the runner's Scale, or [-100, 100] without one.
 func autoRange(local, fromRunner Size) (int, int) {
 	// choose an "M" (magnitude) based on the largest absolute value seen
 	M := 0
@@ -205,7 +205,7 @@ func autoRange(local, fromRunner Size) (int, int) {
 		M = maxInt(M, absInt(s.Max))
 	}
 	if M == 0 {
-		M = 100
+		M = fromRunner.scaleOr(100)
 	}
 	return -M, M
 }
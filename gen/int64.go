@@ -16,7 +16,7 @@ func Int64(size Size) Generator[int64] {
 			min, max = max, min
 		}
 		v := min + int64(r.Intn(int(max-min+1)))
-		return int64ShrinkInit(v, min, max)
+		return int64ShrinkInit(v, min, max, sz.DFS())
 	})
 }
 
@@ -25,12 +25,12 @@ func Int64Range(min, max int64) Generator[int64] {
 	if min > max {
 		min, max = max, min
 	}
-	return From(func(r *rand.Rand, _ Size) (int64, Shrinker[int64]) {
+	return From(func(r *rand.Rand, sz Size) (int64, Shrinker[int64]) {
 		if r == nil {
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
 		}
 		v := min + int64(r.Intn(int(max-min+1)))
-		return int64ShrinkInit(v, min, max)
+		return int64ShrinkInit(v, min, max, sz.DFS())
 	})
 }
 
@@ -39,7 +39,7 @@ func Int64Range(min, max int64) Generator[int64] {
 // int64ShrinkInit initializes the shrinking process for an int64 value.
 // It returns the initial value and a shrinker function that can generate
 // progressively smaller candidates.
-func int64ShrinkInit(start, min, max int64) (int64, Shrinker[int64]) {
+func int64ShrinkInit(start, min, max int64, dfs bool) (int64, Shrinker[int64]) {
 	cur, last := clamp64(start, min, max), clamp64(start, min, max)
 
 	queue := make([]int64, 0, 16)
@@ -95,7 +95,7 @@ func int64ShrinkInit(start, min, max int64) (int64, Shrinker[int64]) {
 		if len(queue) == 0 {
 			return 0, false
 		}
-		if shrinkStrategy == ShrinkStrategyDFS {
+		if dfs {
 			v := queue[len(queue)-1]
 			queue = queue[:len(queue)-1]
 			return v, true
@@ -173,7 +173,7 @@ func stepTowards64(a, b int64) int64 {
 
 // autoRange64 decides the final range for Int64(...) by combining the local "size" and the
 // "size" coming from the runner. We prefer the largest range informed; if nothing is
-// informed, we use [-100, 100].
+// informed, we use the runner's Scale, or [-100, 100] without one.
 func autoRange64(local, fromRunner Size) (int64, int64) {
 	M := int64(0)
 	for _, s := range []Size{local, fromRunner} {
@@ -185,7 +185,7 @@ func autoRange64(local, fromRunner Size) (int64, int64) {
 		}
 	}
 	if M == 0 {
-		M = 100
+		M = int64(fromRunner.scaleOr(100))
 	}
 	return -M, M
 }
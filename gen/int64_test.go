@@ -41,7 +41,7 @@ func TestInt64Range(t *testing.T) {
 
 func TestInt64Shrinker(t *testing.T) {
 	// Test int64 shrinking behavior
-	start, shrink := int64ShrinkInit(50, 0, 100)
+	start, shrink := int64ShrinkInit(50, 0, 100, false)
 
 	if start != 50 {
 		t.Errorf("int64ShrinkInit() start = %d, expected 50", start)
@@ -257,7 +257,7 @@ func TestMaxInt(t *testing.T) {
 }
 
 func TestIntShrinker(t *testing.T) {
-	start, shrink := intShrinkInit(50, 0, 100)
+	start, shrink := intShrinkInit(50, 0, 100, false)
 
 	if start != 50 {
 		t.Errorf("intShrinkInit() start = %d, expected 50", start)
@@ -286,7 +286,7 @@ func TestIntShrinker(t *testing.T) {
 
 func TestIntShrinkerWithAccept(t *testing.T) {
 	// Test shrinking behavior with accept=true
-	_, shrink := intShrinkInit(50, 0, 100)
+	_, shrink := intShrinkInit(50, 0, 100, false)
 
 	// First call with accept=false
 	next1, ok1 := shrink(false)
@@ -309,7 +309,7 @@ func TestIntShrinkerWithAccept(t *testing.T) {
 
 func TestIntShrinkerExhaustion(t *testing.T) {
 	// Test shrinking behavior until exhaustion
-	_, shrink := intShrinkInit(50, 0, 100)
+	_, shrink := intShrinkInit(50, 0, 100, false)
 
 	// Call shrinker many times until it returns false
 	callCount := 0
@@ -336,7 +336,7 @@ func TestIntShrinkerWithDFSSStrategy(t *testing.T) {
 	SetShrinkStrategy(ShrinkStrategyDFS)
 	defer SetShrinkStrategy(ShrinkStrategyBFS) // Reset to default
 
-	_, shrink := intShrinkInit(50, 0, 100)
+	_, shrink := intShrinkInit(50, 0, 100, true)
 
 	// Test that we get a value
 	next, ok := shrink(false)
@@ -355,7 +355,7 @@ func TestIntShrinkerWithInvalidStrategy(t *testing.T) {
 	SetShrinkStrategy("invalid")
 	defer SetShrinkStrategy(ShrinkStrategyBFS) // Reset to default
 
-	_, shrink := intShrinkInit(50, 0, 100)
+	_, shrink := intShrinkInit(50, 0, 100, false)
 
 	// Test that we get a value
 	next, ok := shrink(false)
@@ -386,7 +386,7 @@ func TestIntShrinkerEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			start, shrink := intShrinkInit(tt.start, tt.min, tt.max)
+			start, shrink := intShrinkInit(tt.start, tt.min, tt.max, false)
 
 			if start != tt.start {
 				t.Errorf("intShrinkInit() start = %d, expected %d", start, tt.start)
@@ -408,3 +408,39 @@ func TestIntShrinkerEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestAutoRangeScale(t *testing.T) {
+	tests := []struct {
+		name       string
+		local      Size
+		fromRunner Size
+		expMin     int
+		expMax     int
+	}{
+		{"scale only", Size{}, Size{Scale: 5}, -5, 5},
+		{"local wins over scale", Size{Min: 0, Max: 50}, Size{Scale: 5}, -50, 50},
+		{"runner bounds win over scale", Size{}, Size{Max: 30, Scale: 5}, -30, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			min, max := autoRange(tt.local, tt.fromRunner)
+			if min != tt.expMin || max != tt.expMax {
+				t.Errorf("autoRange(%v, %v) = (%d, %d), expected (%d, %d)",
+					tt.local, tt.fromRunner, min, max, tt.expMin, tt.expMax)
+			}
+		})
+	}
+}
+
+func TestIntFollowsScale(t *testing.T) {
+	gen := Int(Size{})
+	r := rand.New(rand.NewSource(123))
+
+	for i := 0; i < 100; i++ {
+		v, _ := gen.Generate(r, Size{Scale: 3})
+		if v < -3 || v > 3 {
+			t.Fatalf("Int() with Scale 3 = %d, expected [-3, 3]", v)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package gen
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// MapOf generates map[K]V from a key generator and a value generator.
+// - size.Min/Max control the number of entries (default Min=0, Max=16, or Max=Scale when the runner sets one).
+// Keys that collide with an existing entry are drawn again, so the map has at
+// least size.Min entries; MapOf panics if the key generator cannot produce
+// that many distinct keys.
+// Shrink:
+//
+//	(1) remove large blocks of entries (half, quarter, ...), never below size.Min
+//	(2) remove isolated entries (last generated first)
+//	(3) shrink the values in place, one entry at a time (keys are kept)
+func MapOf[K comparable, V any](keys Generator[K], vals Generator[V], size Size) Generator[map[K]V] {
+	return From(func(r *rand.Rand, sz Size) (map[K]V, Shrinker[map[K]V]) {
+		if r == nil {
+			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
+		}
+		size := collectionSize(size, sz)
+		n := size.Min
+		if size.Max > size.Min {
+			n += r.Intn(size.Max - size.Min + 1)
+		}
+
+		// generate entries in a fixed order, redrawing colliding keys
+		var ks []K
+		var vs []V
+		var shks []Shrinker[V]
+		index := make(map[K]struct{}, n)
+		for attempts := 0; len(ks) < n && attempts < 10*n+100; attempts++ {
+			k, _ := keys.Generate(r, sz.inner())
+			if _, dup := index[k]; dup {
+				continue
+			}
+			index[k] = struct{}{}
+			v, s := vals.Generate(r, sz.inner())
+			ks, vs, shks = append(ks, k), append(vs, v), append(shks, s)
+		}
+		if len(ks) < size.Min {
+			panic(fmt.Sprintf("gen.MapOf: key generator produced only %d distinct keys, need at least %d", len(ks), size.Min))
+		}
+
+		build := func(idx []int, vals []V) map[K]V {
+			m := make(map[K]V, len(idx))
+			for j, i := range idx {
+				m[ks[i]] = vals[j]
+			}
+			return m
+		}
+
+		shrink := shrinkByRemoval(len(ks), size.Min, sz.DFS(),
+			func(idx []int) map[K]V { return build(idx, pickValues(vs, idx)) },
+			func(idx []int) Shrinker[map[K]V] {
+				return shrinkInTurn(pickValues(vs, idx), pickValues(shks, idx), func(v []V) map[K]V {
+					return build(idx, v)
+				})
+			})
+		m := make(map[K]V, len(ks))
+		for i, k := range ks {
+			m[k] = vs[i]
+		}
+		return m, shrink
+	})
+}
+
+// SetOf generates a set, represented as map[K]struct{}, from an element generator.
+// - size.Min/Max control the number of elements, as in MapOf.
+// Shrink: removes elements in blocks (half, quarter, ...), then one at a time.
+func SetOf[K comparable](elem Generator[K], size Size) Generator[map[K]struct{}] {
+	return MapOf(elem, Const(struct{}{}), size)
+}
+
+// collectionSize resolves the length bounds of a collection generator:
+// the runner's Min/Max win, then the generator's, then [0, Scale] or [0, 16].
+func collectionSize(size, sz Size) Size {
+	if size.Min == 0 && size.Max == 0 {
+		size.Min, size.Max = 0, sz.scaleOr(16)
+	}
+	if sz.Min != 0 || sz.Max != 0 {
+		size = sz
+	}
+	if size.Min < 0 {
+		size.Min = 0
+	}
+	if size.Max < size.Min {
+		size.Max = size.Min
+	}
+	return size
+}
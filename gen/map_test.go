@@ -0,0 +1,112 @@
+package gen
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMapOf_Size(t *testing.T) {
+	r := rand.New(rand.NewSource(123))
+	gen := MapOf(IntRange(0, 1000), StringAlpha(Size{Min: 1, Max: 3}), Size{Min: 2, Max: 6})
+
+	for i := 0; i < 50; i++ {
+		m, _ := gen.Generate(r, Size{})
+		if len(m) < 2 || len(m) > 6 {
+			t.Fatalf("MapOf() returned %d entries, expected [2, 6]", len(m))
+		}
+	}
+}
+
+func TestMapOf_KeyCollisionsHonorMin(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	// only 5 distinct keys: collisions are frequent
+	gen := MapOf(IntRange(0, 4), Int(Size{}), Size{Min: 5, Max: 5})
+
+	for i := 0; i < 20; i++ {
+		m, _ := gen.Generate(r, Size{})
+		if len(m) != 5 {
+			t.Fatalf("MapOf() returned %d entries, expected 5", len(m))
+		}
+	}
+}
+
+func TestMapOf_PanicsWithoutEnoughKeys(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MapOf() should panic when Min exceeds the distinct keys available")
+		}
+	}()
+	MapOf(IntRange(0, 2), Int(Size{}), Size{Min: 4, Max: 4}).Generate(rand.New(rand.NewSource(1)), Size{})
+}
+
+func TestMapOf_RunnerScale(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gen := MapOf(IntRange(0, 1_000_000), Bool(), Size{})
+
+	for i := 0; i < 20; i++ {
+		m, _ := gen.Generate(r, Size{Scale: 3})
+		if len(m) > 3 {
+			t.Fatalf("MapOf() with Scale=3 returned %d entries", len(m))
+		}
+	}
+}
+
+func TestMapOf_ShrinkRemovesEntries(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	gen := MapOf(IntRange(0, 1000), IntRange(0, 1000), Size{Min: 1, Max: 20})
+	// fails while some value is at least 500
+	fails := func(m map[int]int) bool {
+		for _, v := range m {
+			if v >= 500 {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < 20; i++ {
+		m, s := gen.Generate(r, Size{})
+		if !fails(m) {
+			continue
+		}
+		min := shrinkWhile(m, s, fails, 5000)
+		if len(min) != 1 {
+			t.Fatalf("Expected a single entry after shrinking, got %v", min)
+		}
+		for k, v := range min {
+			if v != 500 {
+				t.Errorf("Expected the value to shrink to 500, got %d", v)
+			}
+			if _, ok := m[k]; !ok {
+				t.Errorf("Shrunk key %d was not in the original map", k)
+			}
+		}
+	}
+}
+
+func TestMapOf_ShrinkHonorsMin(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	gen := MapOf(IntRange(0, 1000), Int(Size{}), Size{Min: 3, Max: 10})
+	m, s := gen.Generate(r, Size{})
+
+	min := shrinkWhile(m, s, func(map[int]int) bool { return true }, 5000)
+	if len(min) != 3 {
+		t.Errorf("Expected shrinking to stop at 3 entries, got %d", len(min))
+	}
+}
+
+func TestSetOf(t *testing.T) {
+	r := rand.New(rand.NewSource(9))
+	gen := SetOf(StringAlpha(Size{Min: 1, Max: 4}), Size{Min: 1, Max: 10})
+
+	for i := 0; i < 20; i++ {
+		set, s := gen.Generate(r, Size{})
+		if len(set) < 1 || len(set) > 10 {
+			t.Fatalf("SetOf() returned %d elements, expected [1, 10]", len(set))
+		}
+		min := shrinkWhile(set, s, func(m map[string]struct{}) bool { return len(m) >= 2 }, 1000)
+		if len(set) >= 2 && len(min) != 2 {
+			t.Errorf("Expected shrinking to 2 elements, got %d", len(min))
+		}
+	}
+}
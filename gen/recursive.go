@@ -0,0 +1,105 @@
+package gen
+
+import (
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+// Deferred builds its generator lazily, on the first call to Generate.
+// It lets a generator refer to itself (or to generators defined later):
+//
+//	var expr gen.Generator[Expr]
+//	expr = gen.OneOf(lit, gen.Deferred(func() gen.Generator[Expr] { return add(expr, expr) }))
+//
+// Deferred does not bound the recursion; prefer Recursive, which does.
+func Deferred[T any](f func() Generator[T]) Generator[T] {
+	var once sync.Once
+	var g Generator[T]
+	return From(func(r *rand.Rand, sz Size) (T, Shrinker[T]) {
+		once.Do(func() { g = f() })
+		return g.Generate(r, sz)
+	})
+}
+
+// Recursive generates recursive values such as trees, JSON documents or ASTs.
+// base generates the leaves; extend receives a generator of subtrees and
+// returns the generator of an inner node built from them:
+//
+//	tree := gen.Recursive(leaf, func(sub gen.Generator[Tree]) gen.Generator[Tree] {
+//	    return gen.Map(gen.SliceOf(sub, gen.Size{Max: 3}), func(c []Tree) Tree { return Tree{Children: c} })
+//	})
+//
+// The size hint bounds the result: with a hint of n (Scale, or 16 when the
+// runner sets none), trees are at most bits.Len(n) levels deep and have at
+// most n inner nodes; past either limit, subtrees fall back to base.
+// Shrink: tries a base case, then replaces the value by each of its subtrees,
+// and finally shrinks it with extend's own shrinker (whose subtrees shrink the
+// same way).
+func Recursive[T any](base Generator[T], extend func(sub Generator[T]) Generator[T]) Generator[T] {
+	return From(func(r *rand.Rand, sz Size) (T, Shrinker[T]) {
+		if r == nil {
+			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
+		}
+		scale := sz.scaleOr(16)
+		budget := scale // inner nodes left
+
+		// leaf is the base case proposed first when shrinking
+		leaf, leafShk := base.Generate(rand.New(rand.NewSource(0)), Size{Scale: 1, Strategy: sz.Strategy}) // #nosec G404 -- Using math/rand for deterministic property-based testing
+
+		var node func(r *rand.Rand, depth int) (T, Shrinker[T])
+		node = func(r *rand.Rand, depth int) (T, Shrinker[T]) {
+			// a third of the nodes are leaves, so trees stay small on average
+			if depth <= 1 || budget <= 0 || r.Intn(3) == 0 {
+				return base.Generate(r, Size{Scale: scale, Strategy: sz.Strategy})
+			}
+			budget--
+
+			var subs []T
+			var subShks []Shrinker[T]
+			sub := From(func(r *rand.Rand, _ Size) (T, Shrinker[T]) {
+				v, s := node(r, depth-1)
+				subs, subShks = append(subs, v), append(subShks, s)
+				return v, s
+			})
+			v, s := extend(sub).Generate(r, Size{Scale: max(budget, 1), Strategy: sz.Strategy})
+
+			cands := append([]T{leaf}, subs...)
+			shks := append([]Shrinker[T]{leafShk}, subShks...)
+			return v, replaceOrShrink(cands, shks, s)
+		}
+		return node(r, bits.Len(uint(scale)))
+	})
+}
+
+// replaceOrShrink proposes each candidate in turn as a replacement for the
+// whole value; when one is accepted, shrinking continues with its shrinker.
+// If every candidate is rejected, it falls back to shrink.
+func replaceOrShrink[T any](cands []T, shks []Shrinker[T], shrink Shrinker[T]) Shrinker[T] {
+	i := -1
+	var cur Shrinker[T]
+	switched := false
+
+	return func(accept bool) (T, bool) {
+		if !switched {
+			switch {
+			case accept && i >= 0:
+				// rebase on the accepted replacement
+				cur, switched = shks[i], true
+			case i+1 < len(cands):
+				i++
+				return cands[i], true
+			default:
+				if i >= 0 {
+					accept = false // the last replacement was rejected
+				}
+				cur, switched = shrink, true
+			}
+		}
+		if cur == nil {
+			var z T
+			return z, false
+		}
+		return cur(accept)
+	}
+}
@@ -0,0 +1,144 @@
+package gen
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type testTree struct {
+	Leaf     int
+	Children []testTree
+}
+
+func (t testTree) depth() int {
+	d := 0
+	for _, c := range t.Children {
+		if cd := c.depth(); cd > d {
+			d = cd
+		}
+	}
+	return d + 1
+}
+
+func (t testTree) nodes() int {
+	n := 1
+	for _, c := range t.Children {
+		n += c.nodes()
+	}
+	return n
+}
+
+func treeGen() Generator[testTree] {
+	leaf := Map(IntRange(0, 100), func(v int) testTree { return testTree{Leaf: v} })
+	return Recursive(leaf, func(sub Generator[testTree]) Generator[testTree] {
+		return Map(SliceOf(sub, Size{Min: 1, Max: 4}), func(c []testTree) testTree {
+			return testTree{Children: c}
+		})
+	})
+}
+
+func TestRecursive_DepthBoundedBySize(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g := treeGen()
+
+	deep := 0
+	for i := 0; i < 200; i++ {
+		tree, _ := g.Generate(r, Size{Scale: 8})
+		if d := tree.depth(); d > 4 { // bits.Len(8) = 4
+			t.Fatalf("Tree depth %d exceeds 4", d)
+		} else if d > 1 {
+			deep++
+		}
+	}
+	if deep == 0 {
+		t.Error("Expected some trees with inner nodes")
+	}
+}
+
+func TestRecursive_SmallSizeGivesLeaves(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g := treeGen()
+
+	for i := 0; i < 20; i++ {
+		tree, _ := g.Generate(r, Size{Scale: 1})
+		if tree.depth() != 1 {
+			t.Fatalf("Expected a leaf with Scale=1, got %+v", tree)
+		}
+	}
+}
+
+func TestRecursive_ShrinksToSubtree(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	g := treeGen()
+	// fails whenever the tree holds a leaf of at least 90
+	fails := func(t testTree) bool { return maxLeaf(t) >= 90 }
+
+	found := 0
+	for i := 0; i < 100 && found < 10; i++ {
+		tree, s := g.Generate(r, Size{Scale: 16})
+		if !fails(tree) || tree.depth() == 1 {
+			continue
+		}
+		found++
+		min := shrinkWhile(tree, s, fails, 5000)
+		if min.depth() != 1 || min.Leaf != 90 {
+			t.Errorf("Expected shrinking to a single leaf 90, got %+v (from %d nodes)", min, tree.nodes())
+		}
+	}
+	if found == 0 {
+		t.Fatal("Expected at least one failing tree")
+	}
+}
+
+func maxLeaf(t testTree) int {
+	m := t.Leaf
+	for _, c := range t.Children {
+		if v := maxLeaf(c); v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func TestDeferred(t *testing.T) {
+	calls := 0
+	g := Deferred(func() Generator[int] {
+		calls++
+		return IntRange(1, 3)
+	})
+	if calls != 0 {
+		t.Fatal("Deferred() should not build the generator before Generate")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5; i++ {
+		if v, _ := g.Generate(r, Size{}); v < 1 || v > 3 {
+			t.Errorf("Deferred() generated %d, expected [1, 3]", v)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected the generator to be built once, got %d", calls)
+	}
+}
+
+func TestDeferred_SelfReference(t *testing.T) {
+	var list Generator[[]int]
+	list = OneOf(
+		Const([]int(nil)),
+		Bind(IntRange(0, 9), func(v int) Generator[[]int] {
+			return Map(Deferred(func() Generator[[]int] { return list }), func(rest []int) []int {
+				return append([]int{v}, rest...)
+			})
+		}),
+	)
+
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 20; i++ {
+		vals, _ := list.Generate(r, Size{})
+		for _, v := range vals {
+			if v < 0 || v > 9 {
+				t.Fatalf("Unexpected element %d", v)
+			}
+		}
+	}
+}
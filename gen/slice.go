@@ -3,10 +3,11 @@ package gen
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 )
 
 // SliceOf generates []T from an element generator.
-// - size.Min/Max control the length (default Min=0, Max=16).
+// - size.Min/Max control the length (default Min=0, Max=16, or Max=Scale when the runner sets one).
 // Shrink:
 //
 //	(1) remove large blocks (half, quarter, ...) → remove indices
@@ -14,12 +15,14 @@ import (
 //	(3) try shrink on elements (propagating accept)
 func SliceOf[T any](elem Generator[T], size Size) Generator[[]T] {
 	return From(func(r *rand.Rand, sz Size) ([]T, Shrinker[[]T]) {
+		dfs := sz.DFS()
 		if r == nil {
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
 		}
+		size := size // per-call copy: the defaults below depend on sz
 		// defaults
 		if size.Min == 0 && size.Max == 0 {
-			size.Min, size.Max = 0, 16
+			size.Min, size.Max = 0, sz.scaleOr(16)
 		}
 		if sz.Min != 0 || sz.Max != 0 {
 			size = sz
@@ -38,7 +41,7 @@ func SliceOf[T any](elem Generator[T], size Size) Generator[[]T] {
 		vals := make([]T, n)
 		shks := make([]Shrinker[T], n)
 		for i := 0; i < n; i++ {
-			v, s := elem.Generate(r, Size{})
+			v, s := elem.Generate(r, sz.inner())
 			vals[i], shks[i] = v, s
 		}
 		cur := append(([]T)(nil), vals...) // snapshot
@@ -104,7 +107,7 @@ func SliceOf[T any](elem Generator[T], size Size) Generator[[]T] {
 			if len(queue) == 0 {
 				return nil, false
 			}
-			if shrinkStrategy == ShrinkStrategyDFS {
+			if dfs {
 				v := queue[len(queue)-1]
 				queue = queue[:len(queue)-1]
 				return v, true
@@ -145,6 +148,108 @@ func SliceOf[T any](elem Generator[T], size Size) Generator[[]T] {
 	})
 }
 
+// SliceOfUnique generates []T whose elements have distinct keys, as computed
+// by key (use an identity function for comparable elements).
+// - size.Min/Max control the length, as in SliceOf. Elements whose key is
+// already taken are drawn again; SliceOfUnique panics if the element generator
+// cannot produce size.Min distinct keys.
+// Shrink: removes elements (blocks, then one at a time, never below size.Min),
+// then shrinks the elements in place, skipping candidates whose key collides
+// with another element.
+func SliceOfUnique[T any, K comparable](elem Generator[T], key func(T) K, size Size) Generator[[]T] {
+	return From(func(r *rand.Rand, sz Size) ([]T, Shrinker[[]T]) {
+		if r == nil {
+			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
+		}
+		size := collectionSize(size, sz)
+		n := size.Min
+		if size.Max > size.Min {
+			n += r.Intn(size.Max - size.Min + 1)
+		}
+
+		var vals []T
+		var shks []Shrinker[T]
+		keys := make(map[K]struct{}, n)
+		for attempts := 0; len(vals) < n && attempts < 10*n+100; attempts++ {
+			v, s := elem.Generate(r, sz.inner())
+			k := key(v)
+			if _, dup := keys[k]; dup {
+				continue
+			}
+			keys[k] = struct{}{}
+			vals, shks = append(vals, v), append(shks, s)
+		}
+		if len(vals) < size.Min {
+			panic(fmt.Sprintf("gen.SliceOfUnique: element generator produced only %d distinct keys, need at least %d", len(vals), size.Min))
+		}
+
+		unique := func(cur []T, i int, v T) bool {
+			k := key(v)
+			for j, o := range cur {
+				if j != i && key(o) == k {
+					return false
+				}
+			}
+			return true
+		}
+		return slicePreserving(vals, shks, size.Min, sz.DFS(), unique)
+	})
+}
+
+// SliceOfSorted generates []T in non-decreasing order according to less.
+// - size.Min/Max control the length, as in SliceOf.
+// Shrink: removes elements (blocks, then one at a time, never below size.Min),
+// then shrinks the elements in place, skipping candidates that would fall
+// outside the range allowed by their neighbours.
+func SliceOfSorted[T any](elem Generator[T], less func(a, b T) bool, size Size) Generator[[]T] {
+	return From(func(r *rand.Rand, sz Size) ([]T, Shrinker[[]T]) {
+		if r == nil {
+			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
+		}
+		size := collectionSize(size, sz)
+		n := size.Min
+		if size.Max > size.Min {
+			n += r.Intn(size.Max - size.Min + 1)
+		}
+
+		type element struct {
+			v T
+			s Shrinker[T]
+		}
+		elems := make([]element, n)
+		for i := range elems {
+			elems[i].v, elems[i].s = elem.Generate(r, sz.inner())
+		}
+		sort.SliceStable(elems, func(i, j int) bool { return less(elems[i].v, elems[j].v) })
+
+		vals := make([]T, n)
+		shks := make([]Shrinker[T], n)
+		for i, e := range elems {
+			vals[i], shks[i] = e.v, e.s
+		}
+
+		inOrder := func(cur []T, i int, v T) bool {
+			if i > 0 && less(v, cur[i-1]) {
+				return false
+			}
+			return i == len(cur)-1 || !less(cur[i+1], v)
+		}
+		return slicePreserving(vals, shks, size.Min, sz.DFS(), inOrder)
+	})
+}
+
+// slicePreserving returns vals with a shrinker that removes elements and then
+// shrinks them in place, proposing only element candidates accepted by valid.
+// Removing elements never breaks uniqueness or ordering, so it is unrestricted.
+func slicePreserving[T any](vals []T, shks []Shrinker[T], min int, dfs bool, valid func(cur []T, i int, v T) bool) ([]T, Shrinker[[]T]) {
+	build := func(v []T) []T { return v }
+	return append(([]T)(nil), vals...), shrinkByRemoval(len(vals), min, dfs,
+		func(idx []int) []T { return pickValues(vals, idx) },
+		func(idx []int) Shrinker[[]T] {
+			return shrinkInTurnWhere(pickValues(vals, idx), pickValues(shks, idx), valid, build)
+		})
+}
+
 // sig creates a simplified textual signature of a generic slice.
 // For shrinking dedup purposes in tests, this is sufficient.
 func sig[T any](s []T) string { return fmt.Sprintf("%#v", s) }
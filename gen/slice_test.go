@@ -293,3 +293,136 @@ func TestSliceOfWithFloatElements(t *testing.T) {
 		t.Error("SliceOf(Float64()).Generate() returned nil shrinker")
 	}
 }
+
+func TestSliceOfFollowsScale(t *testing.T) {
+	r := rand.New(rand.NewSource(123))
+	gen := SliceOf(Int(Size{}), Size{})
+
+	// A small scale must not stick to later calls with a larger one.
+	longest := 0
+	for _, scale := range []int{1, 1, 50, 50, 50, 50, 50} {
+		value, _ := gen.Generate(r, Size{Scale: scale})
+		if len(value) > scale {
+			t.Fatalf("SliceOf() with Scale %d returned length %d", scale, len(value))
+		}
+		for _, v := range value {
+			if v < -scale || v > scale {
+				t.Fatalf("SliceOf() with Scale %d returned element %d", scale, v)
+			}
+		}
+		if len(value) > longest {
+			longest = len(value)
+		}
+	}
+	if longest <= 1 {
+		t.Errorf("Expected larger slices once the scale grows, longest was %d", longest)
+	}
+}
+
+func TestSliceOfExplicitSizeIgnoresScale(t *testing.T) {
+	r := rand.New(rand.NewSource(123))
+	gen := SliceOf(Int(Size{}), Size{Min: 3, Max: 5})
+
+	for i := 0; i < 50; i++ {
+		value, _ := gen.Generate(r, Size{Scale: 1})
+		if len(value) < 3 || len(value) > 5 {
+			t.Fatalf("SliceOf() length = %d, expected [3, 5]", len(value))
+		}
+	}
+}
+
+func TestSliceOfUnique(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	// a small key space makes collisions frequent
+	gen := SliceOfUnique(IntRange(0, 20), func(v int) int { return v }, Size{Min: 10, Max: 15})
+
+	for i := 0; i < 30; i++ {
+		vals, _ := gen.Generate(r, Size{})
+		if len(vals) < 10 || len(vals) > 15 {
+			t.Fatalf("SliceOfUnique() returned %d elements, expected [10, 15]", len(vals))
+		}
+		assertUnique(t, vals)
+	}
+}
+
+func TestSliceOfUnique_ShrinkPreservesUniqueness(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	gen := SliceOfUnique(IntRange(0, 1000), func(v int) int { return v }, Size{Min: 3, Max: 10})
+
+	for i := 0; i < 10; i++ {
+		vals, s := gen.Generate(r, Size{})
+		min := shrinkWhile(vals, s, func(c []int) bool {
+			assertUnique(t, c)
+			return true
+		}, 2000)
+		if len(min) != 3 {
+			t.Errorf("Expected shrinking to stop at 3 elements, got %v", min)
+		}
+		// colliding candidates are skipped, so elements settle near zero
+		for _, v := range min {
+			if v >= 10 {
+				t.Errorf("Expected elements to shrink close to zero, got %v", min)
+			}
+		}
+	}
+}
+
+func TestSliceOfUnique_KeyFunction(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	gen := SliceOfUnique(StringAlpha(Size{Min: 1, Max: 3}), func(s string) byte { return s[0] }, Size{Min: 5, Max: 5})
+
+	vals, _ := gen.Generate(r, Size{})
+	first := map[byte]bool{}
+	for _, s := range vals {
+		if first[s[0]] {
+			t.Fatalf("SliceOfUnique() returned two strings starting with %q: %v", s[0], vals)
+		}
+		first[s[0]] = true
+	}
+}
+
+func TestSliceOfUnique_PanicsWithoutEnoughKeys(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("SliceOfUnique() should panic when Min exceeds the distinct keys available")
+		}
+	}()
+	SliceOfUnique(Bool(), func(b bool) bool { return b }, Size{Min: 3, Max: 3}).Generate(rand.New(rand.NewSource(1)), Size{})
+}
+
+func TestSliceOfSorted(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	gen := SliceOfSorted(IntRange(-100, 100), func(a, b int) bool { return a < b }, Size{Min: 1, Max: 20})
+
+	for i := 0; i < 30; i++ {
+		vals, s := gen.Generate(r, Size{})
+		assertSorted(t, vals)
+		min := shrinkWhile(vals, s, func(c []int) bool {
+			assertSorted(t, c)
+			return len(c) >= 2 && c[len(c)-1] >= 50
+		}, 2000)
+		if len(vals) >= 2 && vals[len(vals)-1] >= 50 && (len(min) != 2 || min[1] != 50) {
+			t.Errorf("Expected two elements ending in 50, got %v (from %v)", min, vals)
+		}
+	}
+}
+
+func assertUnique(t *testing.T, vals []int) {
+	t.Helper()
+	seen := map[int]bool{}
+	for _, v := range vals {
+		if seen[v] {
+			t.Fatalf("Duplicate element %d in %v", v, vals)
+		}
+		seen[v] = true
+	}
+}
+
+func assertSorted(t *testing.T, vals []int) {
+	t.Helper()
+	for i := 1; i < len(vals); i++ {
+		if vals[i] < vals[i-1] {
+			t.Fatalf("Unsorted slice %v", vals)
+		}
+	}
+}
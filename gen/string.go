@@ -27,10 +27,11 @@ const (
 )
 
 // String generates strings using an alphabet (set of runes) and a Size.
-// - If size.Min/Max = 0, uses default: Min=0, Max=32.
+// - If size.Min/Max = 0, uses default: Min=0, Max=32 (Max=Scale when the runner sets one).
 // - If alphabet is empty, uses AlphabetAlphaNum.
 func String(alphabet string, size Size) Generator[string] {
 	return From(func(r *rand.Rand, sz Size) (string, Shrinker[string]) {
+		dfs := sz.DFS()
 		if r == nil {
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
 		}
@@ -38,8 +39,9 @@ func String(alphabet string, size Size) Generator[string] {
 		if len(alphabet) == 0 {
 			alphabet = AlphabetAlphaNum
 		}
+		size := size // per-call copy: the defaults below depend on sz
 		if size.Min == 0 && size.Max == 0 {
-			size.Min, size.Max = 0, 32
+			size.Min, size.Max = 0, sz.scaleOr(32)
 		}
 		if sz.Min != 0 || sz.Max != 0 { // allow external override
 			size = sz
@@ -107,7 +109,7 @@ func String(alphabet string, size Size) Generator[string] {
 			if len(queue) == 0 {
 				return "", false
 			}
-			if shrinkStrategy == ShrinkStrategyDFS {
+			if dfs {
 				v := queue[len(queue)-1]
 				queue = queue[:len(queue)-1]
 				return v, true
@@ -118,3 +118,15 @@ func TestStringShrinker(t *testing.T) {
 		t.Errorf("String shrinker returned longer string: %q (len=%d) vs %q (len=%d)", next, len(next), value, len(value))
 	}
 }
+
+func TestStringFollowsScale(t *testing.T) {
+	gen := String("abc", Size{})
+	r := rand.New(rand.NewSource(123))
+
+	for i := 0; i < 50; i++ {
+		value, _ := gen.Generate(r, Size{Scale: 4})
+		if len(value) > 4 {
+			t.Fatalf("String() with Scale 4 returned length %d", len(value))
+		}
+	}
+}
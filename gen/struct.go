@@ -0,0 +1,304 @@
+package gen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// structTag is the struct tag key read by Struct.
+const structTag = "rapidx"
+
+// StructOption customizes how Struct generates one field.
+type StructOption struct {
+	field string
+	gen   Generator[reflect.Value]
+	typ   reflect.Type
+}
+
+// Field overrides the generator of the field with the given name.
+// Fields of nested structs are addressed with a dotted path, e.g. "Address.City".
+func Field[F any](name string, g Generator[F]) StructOption {
+	return StructOption{
+		field: name,
+		gen:   erase(g),
+		typ:   reflect.TypeOf((*F)(nil)).Elem(),
+	}
+}
+
+// Struct derives a generator for the struct type T by reflection.
+//
+// Every exported field gets a generator built from the primitive generators
+// (Int, Uint, Float64, Bool, String, SliceOf, ArrayOf and nested structs).
+// Unexported fields are left at their zero value. A field can be customized:
+//   - by name, with gen.Field("Age", gen.IntRange(0, 120));
+//   - by tag, with `rapidx:"range=1..100"` (numbers), `rapidx:"len=0..10"`
+//     (strings and slices), `rapidx:"alphabet=digits"` (strings; also lower,
+//     upper, alpha, alphanum, ascii or a literal set of characters), or
+//     `rapidx:"-"` to leave the field at its zero value.
+//
+// Struct panics if T is not a struct, if a field has a type it cannot
+// generate and no override, or if an option names an unknown field.
+// Shrink: fields are shrunk one at a time, each with its own shrinker,
+// while the other fields hold their current values.
+func Struct[T any](opts ...StructOption) Generator[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("gen.Struct: %s is not a struct", typ))
+	}
+	overrides := make(map[string]StructOption, len(opts))
+	for _, o := range opts {
+		overrides[o.field] = o
+	}
+	g := structValueGen(typ, "", overrides)
+	for name := range overrides {
+		panic(fmt.Sprintf("gen.Struct: %s has no field %q", typ, name))
+	}
+	return Map(g, func(v reflect.Value) T { return v.Interface().(T) })
+}
+
+// -------------------- implementation --------------------
+
+// erase turns a typed generator into a generator of reflect.Value.
+func erase[F any](g Generator[F]) Generator[reflect.Value] {
+	return Map(g, func(v F) reflect.Value { return reflect.ValueOf(&v).Elem() })
+}
+
+// structValueGen builds the generator of a struct type. Options consumed for
+// its fields (addressed by prefix+name) are removed from overrides.
+func structValueGen(typ reflect.Type, prefix string, overrides map[string]StructOption) Generator[reflect.Value] {
+	type field struct {
+		index int
+		gen   Generator[reflect.Value]
+	}
+	var fields []field
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		path := prefix + sf.Name
+		o, overridden := overrides[path]
+		if !sf.IsExported() {
+			if overridden {
+				panic(fmt.Sprintf("gen.Struct: field %s is unexported and cannot be set", path))
+			}
+			continue
+		}
+		if overridden {
+			if !o.typ.AssignableTo(sf.Type) {
+				panic(fmt.Sprintf("gen.Struct: field %s has type %s, override generates %s", path, sf.Type, o.typ))
+			}
+			delete(overrides, path)
+			fields = append(fields, field{index: i, gen: o.gen})
+			continue
+		}
+		tag := parseStructTag(sf.Tag.Get(structTag))
+		if tag.skip {
+			continue
+		}
+		g, err := typeValueGen(sf.Type, tag, path+".", overrides)
+		if err != nil {
+			panic(fmt.Sprintf("gen.Struct: field %s: %v", path, err))
+		}
+		fields = append(fields, field{index: i, gen: g})
+	}
+
+	return From(func(r *rand.Rand, sz Size) (reflect.Value, Shrinker[reflect.Value]) {
+		vals := make([]reflect.Value, len(fields))
+		shks := make([]Shrinker[reflect.Value], len(fields))
+		for i, f := range fields {
+			vals[i], shks[i] = f.gen.Generate(r, sz)
+		}
+		build := func(vs []reflect.Value) reflect.Value {
+			out := reflect.New(typ).Elem()
+			for i, f := range fields {
+				out.Field(f.index).Set(vs[i])
+			}
+			return out
+		}
+		return build(vals), shrinkInTurn(vals, shks, build)
+	})
+}
+
+// typeValueGen builds the generator for a value of type typ.
+func typeValueGen(typ reflect.Type, tag structTagSpec, prefix string, overrides map[string]StructOption) (Generator[reflect.Value], error) {
+	if tag.rng != nil && !isNumericKind(typ.Kind()) {
+		return nil, fmt.Errorf("range is only supported on numeric fields, not %s", typ)
+	}
+	convert := func(v reflect.Value) reflect.Value { return v.Convert(typ) }
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Map(intKindGen(typ.Bits(), tag.rng), func(v int64) reflect.Value {
+			return reflect.ValueOf(v).Convert(typ)
+		}), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Map(uintKindGen(typ.Bits(), tag.rng), func(v uint64) reflect.Value {
+			return reflect.ValueOf(v).Convert(typ)
+		}), nil
+
+	case reflect.Float32, reflect.Float64:
+		g := Float64(Size{})
+		if tag.rng != nil {
+			g = Float64Range(tag.rng[0], tag.rng[1], false, false)
+		}
+		return Map(g, func(v float64) reflect.Value { return reflect.ValueOf(v).Convert(typ) }), nil
+
+	case reflect.Bool:
+		return Map(erase(Bool()), convert), nil
+
+	case reflect.String:
+		return Map(erase(String(tag.alphabet, tag.length)), convert), nil
+
+	case reflect.Slice:
+		elem, err := typeValueGen(typ.Elem(), structTagSpec{}, prefix, overrides)
+		if err != nil {
+			return nil, err
+		}
+		return Map(SliceOf(elem, tag.length), func(vs []reflect.Value) reflect.Value {
+			out := reflect.MakeSlice(typ, len(vs), len(vs))
+			for i, v := range vs {
+				out.Index(i).Set(v)
+			}
+			return out
+		}), nil
+
+	case reflect.Array:
+		elem, err := typeValueGen(typ.Elem(), structTagSpec{}, prefix, overrides)
+		if err != nil {
+			return nil, err
+		}
+		return Map(ArrayOf(elem, typ.Len()), func(vs []reflect.Value) reflect.Value {
+			out := reflect.New(typ).Elem()
+			for i, v := range vs {
+				out.Index(i).Set(v)
+			}
+			return out
+		}), nil
+
+	case reflect.Struct:
+		return structValueGen(typ, prefix, overrides), nil
+
+	case reflect.Pointer:
+		elem, err := typeValueGen(typ.Elem(), tag, prefix, overrides)
+		if err != nil {
+			return nil, err
+		}
+		return Map(elem, func(v reflect.Value) reflect.Value {
+			p := reflect.New(typ.Elem())
+			p.Elem().Set(v)
+			return p
+		}), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s (use gen.Field to provide a generator)", typ)
+}
+
+// intKindGen generates signed integers that fit in the given number of bits.
+// Without a range, it follows Int's sizing: [-Scale, Scale], or [-100, 100].
+func intKindGen(bits int, rng *[2]float64) Generator[int64] {
+	lo, hi := int64(math.MinInt64)>>(64-bits), int64(math.MaxInt64)>>(64-bits)
+	if rng != nil {
+		return Int64Range(clamp64(int64(rng[0]), lo, hi), clamp64(int64(rng[1]), lo, hi))
+	}
+	return From(func(r *rand.Rand, sz Size) (int64, Shrinker[int64]) {
+		min, max := autoRange64(Size{}, sz)
+		return Int64Range(clamp64(min, lo, hi), clamp64(max, lo, hi)).Generate(r, sz)
+	})
+}
+
+// uintKindGen generates unsigned integers that fit in the given number of bits.
+// Without a range, it follows Uint's sizing: [0, Scale], or [0, 100].
+func uintKindGen(bits int, rng *[2]float64) Generator[uint64] {
+	hi := uint64(math.MaxUint64) >> (64 - bits)
+	if rng != nil {
+		min, max := uint64(math.Max(rng[0], 0)), uint64(math.Max(rng[1], 0))
+		return Uint64Range(clampU64(min, 0, hi), clampU64(max, 0, hi))
+	}
+	return From(func(r *rand.Rand, sz Size) (uint64, Shrinker[uint64]) {
+		min, max := autoRangeUint64(Size{}, sz)
+		return Uint64Range(clampU64(min, 0, hi), clampU64(max, 0, hi)).Generate(r, sz)
+	})
+}
+
+// isNumericKind reports whether values of kind k accept a range tag.
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Pointer:
+		return true
+	}
+	return false
+}
+
+// structTagSpec is the parsed form of a `rapidx:"..."` struct tag.
+type structTagSpec struct {
+	skip     bool
+	rng      *[2]float64
+	length   Size
+	alphabet string
+}
+
+// alphabetPresets maps the names accepted by the alphabet tag to alphabets.
+var alphabetPresets = map[string]string{
+	"lower":    AlphabetLower,
+	"upper":    AlphabetUpper,
+	"alpha":    AlphabetAlpha,
+	"digits":   AlphabetDigits,
+	"alphanum": AlphabetAlphaNum,
+	"ascii":    AlphabetASCII,
+}
+
+// parseStructTag parses a comma-separated list of key=value settings.
+// Malformed settings panic, since they are programming errors.
+func parseStructTag(tag string) structTagSpec {
+	var spec structTagSpec
+	if tag == "" {
+		return spec
+	}
+	if tag == "-" {
+		spec.skip = true
+		return spec
+	}
+	for _, part := range strings.Split(tag, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			panic(fmt.Sprintf("gen.Struct: malformed tag setting %q", part))
+		}
+		switch key {
+		case "range":
+			lo, hi := parseTagRange(val)
+			spec.rng = &[2]float64{lo, hi}
+		case "len":
+			lo, hi := parseTagRange(val)
+			spec.length = Size{Min: int(lo), Max: int(hi)}
+		case "alphabet":
+			if preset, ok := alphabetPresets[val]; ok {
+				val = preset
+			}
+			spec.alphabet = val
+		default:
+			panic(fmt.Sprintf("gen.Struct: unknown tag setting %q", key))
+		}
+	}
+	return spec
+}
+
+// parseTagRange parses "a..b" into its bounds.
+func parseTagRange(s string) (float64, float64) {
+	a, b, ok := strings.Cut(s, "..")
+	if !ok {
+		panic(fmt.Sprintf("gen.Struct: malformed range %q (expected min..max)", s))
+	}
+	lo, err1 := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	hi, err2 := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if err1 != nil || err2 != nil {
+		panic(fmt.Sprintf("gen.Struct: malformed range %q (expected min..max)", s))
+	}
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return lo, hi
+}
@@ -0,0 +1,178 @@
+package gen
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+type structAddress struct {
+	City string `rapidx:"alphabet=lower,len=1..8"`
+	Zip  uint16
+}
+
+type structUser struct {
+	Name     string `rapidx:"alphabet=alpha,len=3..10"`
+	Age      int    `rapidx:"range=18..99"`
+	Score    float64
+	Admin    bool
+	Level    int8
+	Tags     []string `rapidx:"len=0..3"`
+	Pair     [2]int
+	Address  structAddress
+	Manager  *structAddress
+	Ignored  int `rapidx:"-"`
+	internal int
+}
+
+func TestStruct_RespectsTags(t *testing.T) {
+	gen := Struct[structUser]()
+	r := rand.New(rand.NewSource(123))
+
+	for i := 0; i < 200; i++ {
+		u, _ := gen.Generate(r, Size{})
+		if len(u.Name) < 3 || len(u.Name) > 10 || strings.Trim(u.Name, AlphabetAlpha) != "" {
+			t.Fatalf("Name = %q, expected 3..10 letters", u.Name)
+		}
+		if u.Age < 18 || u.Age > 99 {
+			t.Fatalf("Age = %d, expected [18, 99]", u.Age)
+		}
+		if len(u.Tags) > 3 {
+			t.Fatalf("Tags = %v, expected at most 3", u.Tags)
+		}
+		if len(u.Address.City) < 1 || len(u.Address.City) > 8 || strings.Trim(u.Address.City, AlphabetLower) != "" {
+			t.Fatalf("Address.City = %q, expected 1..8 lowercase letters", u.Address.City)
+		}
+		if u.Manager == nil {
+			t.Fatal("Manager should be generated")
+		}
+		if u.Ignored != 0 || u.internal != 0 {
+			t.Fatalf("skipped fields should stay zero, got %d and %d", u.Ignored, u.internal)
+		}
+	}
+}
+
+func TestStruct_FollowsScale(t *testing.T) {
+	gen := Struct[structUser]()
+	r := rand.New(rand.NewSource(123))
+
+	for i := 0; i < 100; i++ {
+		u, _ := gen.Generate(r, Size{Scale: 5})
+		if u.Level < -5 || u.Level > 5 || u.Pair[0] < -5 || u.Pair[0] > 5 {
+			t.Fatalf("Expected untagged numbers in [-5, 5], got Level=%d Pair=%v", u.Level, u.Pair)
+		}
+		if u.Address.Zip > 5 {
+			t.Fatalf("Expected Zip in [0, 5], got %d", u.Address.Zip)
+		}
+	}
+}
+
+func TestStruct_FieldOverride(t *testing.T) {
+	gen := Struct[structUser](
+		Field("Age", Const(42)),
+		Field("Address.City", Const("Recife")),
+	)
+	r := rand.New(rand.NewSource(123))
+
+	u, _ := gen.Generate(r, Size{})
+	if u.Age != 42 {
+		t.Errorf("Age = %d, expected override 42", u.Age)
+	}
+	if u.Address.City != "Recife" {
+		t.Errorf("Address.City = %q, expected override", u.Address.City)
+	}
+}
+
+func TestStruct_ShrinksFieldsIndependently(t *testing.T) {
+	type point struct {
+		X int `rapidx:"range=0..1000"`
+		Y int `rapidx:"range=0..1000"`
+		S string
+	}
+	gen := Struct[point]()
+	fails := func(p point) bool { return p.X >= 10 && p.Y >= 20 }
+
+	r := rand.New(rand.NewSource(7))
+	found := false
+	for i := 0; i < 50; i++ {
+		v, s := gen.Generate(r, Size{})
+		if !fails(v) {
+			continue
+		}
+		found = true
+		min := shrinkWhile(v, s, fails, 2000)
+		if !fails(min) {
+			t.Fatalf("Shrunk value %+v no longer fails", min)
+		}
+		// every field shrinks on its own, close to its smallest failing value
+		if min.X > 15 || min.Y > 25 || len(min.S) > 3 {
+			t.Errorf("Expected X, Y and S to shrink independently, got %+v (from %+v)", min, v)
+		}
+	}
+	if !found {
+		t.Fatal("Expected at least one failing value")
+	}
+}
+
+func TestStruct_Panics(t *testing.T) {
+	type withChan struct {
+		C chan int
+	}
+	type withHidden struct {
+		hidden int
+	}
+
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"not a struct", func() { Struct[int]() }},
+		{"unsupported field", func() { Struct[withChan]() }},
+		{"unknown field override", func() { Struct[structAddress](Field("Nope", Const(1))) }},
+		{"wrong override type", func() { Struct[structAddress](Field("Zip", Const("x"))) }},
+		{"unexported override", func() { Struct[withHidden](Field("hidden", Const(1))) }},
+		{"malformed tag", func() { parseStructTag("range") }},
+		{"unknown tag", func() { parseStructTag("size=1") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+func TestStruct_UnsupportedFieldWithOverride(t *testing.T) {
+	type withChan struct {
+		C chan int
+		N int
+	}
+	ch := make(chan int)
+	gen := Struct[withChan](Field("C", Const(ch)))
+
+	v, _ := gen.Generate(rand.New(rand.NewSource(1)), Size{})
+	if v.C != ch {
+		t.Error("Expected the overridden channel")
+	}
+}
+
+func TestParseStructTag(t *testing.T) {
+	spec := parseStructTag("range=-5..5, len=1..3, alphabet=xyz")
+	if spec.rng == nil || spec.rng[0] != -5 || spec.rng[1] != 5 {
+		t.Errorf("range = %v, expected [-5 5]", spec.rng)
+	}
+	if spec.length != (Size{Min: 1, Max: 3}) {
+		t.Errorf("len = %v, expected {1 3}", spec.length)
+	}
+	if spec.alphabet != "xyz" {
+		t.Errorf("alphabet = %q, expected xyz", spec.alphabet)
+	}
+	if !parseStructTag("-").skip {
+		t.Error("Expected \"-\" to skip the field")
+	}
+}
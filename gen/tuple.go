@@ -0,0 +1,71 @@
+package gen
+
+import "math/rand"
+
+// Pair holds two values generated independently by Tuple2.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Triple holds three values generated independently by Tuple3.
+type Triple[A, B, C any] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+// Tuple2 generates a Pair from two independent generators.
+// Shrink: shrinks First with its own shrinker while Second is held fixed,
+// then shrinks Second while First holds its minimum (nothing is regenerated).
+func Tuple2[A, B any](ga Generator[A], gb Generator[B]) Generator[Pair[A, B]] {
+	return From(func(r *rand.Rand, sz Size) (Pair[A, B], Shrinker[Pair[A, B]]) {
+		if r == nil {
+			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
+		}
+		a, sa := ga.Generate(r, sz)
+		b, sb := gb.Generate(r, sz)
+		build := func(vs []any) Pair[A, B] {
+			return Pair[A, B]{First: as[A](vs[0]), Second: as[B](vs[1])}
+		}
+		cur := []any{a, b}
+		shks := []Shrinker[any]{eraseShrinker(sa), eraseShrinker(sb)}
+		return build(cur), shrinkInTurn(cur, shks, build)
+	})
+}
+
+// Tuple3 generates a Triple from three independent generators.
+// Shrink: shrinks each component in turn while the others are held fixed.
+func Tuple3[A, B, C any](ga Generator[A], gb Generator[B], gc Generator[C]) Generator[Triple[A, B, C]] {
+	return From(func(r *rand.Rand, sz Size) (Triple[A, B, C], Shrinker[Triple[A, B, C]]) {
+		if r == nil {
+			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
+		}
+		a, sa := ga.Generate(r, sz)
+		b, sb := gb.Generate(r, sz)
+		c, sc := gc.Generate(r, sz)
+		build := func(vs []any) Triple[A, B, C] {
+			return Triple[A, B, C]{First: as[A](vs[0]), Second: as[B](vs[1]), Third: as[C](vs[2])}
+		}
+		cur := []any{a, b, c}
+		shks := []Shrinker[any]{eraseShrinker(sa), eraseShrinker(sb), eraseShrinker(sc)}
+		return build(cur), shrinkInTurn(cur, shks, build)
+	})
+}
+
+// eraseShrinker adapts a typed shrinker to produce values of type any.
+func eraseShrinker[T any](s Shrinker[T]) Shrinker[any] {
+	if s == nil {
+		return nil
+	}
+	return func(accept bool) (any, bool) {
+		v, ok := s(accept)
+		return v, ok
+	}
+}
+
+// as converts v back to T; a nil interface yields T's zero value.
+func as[T any](v any) T {
+	x, _ := v.(T)
+	return x
+}
@@ -0,0 +1,91 @@
+package gen
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTuple2(t *testing.T) {
+	gen := Tuple2(IntRange(0, 10), StringAlpha(Size{Min: 1, Max: 3}))
+	r := rand.New(rand.NewSource(123))
+
+	for i := 0; i < 50; i++ {
+		p, _ := gen.Generate(r, Size{})
+		if p.First < 0 || p.First > 10 {
+			t.Fatalf("First = %d, expected [0, 10]", p.First)
+		}
+		if len(p.Second) < 1 || len(p.Second) > 3 {
+			t.Fatalf("Second = %q, expected length [1, 3]", p.Second)
+		}
+	}
+}
+
+func TestTuple2_ShrinksEachComponent(t *testing.T) {
+	gen := Tuple2(IntRange(0, 1000), IntRange(0, 1000))
+	fails := func(p Pair[int, int]) bool { return p.First >= 100 && p.Second >= 7 }
+
+	r := rand.New(rand.NewSource(3))
+	found := false
+	for i := 0; i < 50; i++ {
+		v, s := gen.Generate(r, Size{})
+		if !fails(v) {
+			continue
+		}
+		found = true
+		min := shrinkWhile(v, s, fails, 2000)
+		if !fails(min) {
+			t.Fatalf("Shrunk value %+v no longer fails", min)
+		}
+		if min.First > 105 || min.Second > 10 {
+			t.Errorf("Expected both components to shrink, got %+v (from %+v)", min, v)
+		}
+	}
+	if !found {
+		t.Fatal("Expected at least one failing value")
+	}
+}
+
+func TestTuple2_HoldsOtherComponentFixed(t *testing.T) {
+	gen := Tuple2(IntRange(0, 1000), IntRange(0, 1000))
+	r := rand.New(rand.NewSource(5))
+	v, s := gen.Generate(r, Size{})
+
+	// while the first component still has candidates, the second never changes
+	for i := 0; i < 5; i++ {
+		next, ok := s(false)
+		if !ok {
+			break
+		}
+		if next.Second != v.Second && next.First != v.First {
+			t.Fatalf("Expected one component to change at a time, got %+v from %+v", next, v)
+		}
+	}
+}
+
+func TestTuple3(t *testing.T) {
+	gen := Tuple3(IntRange(0, 1000), Bool(), IntRange(0, 1000))
+	fails := func(p Triple[int, bool, int]) bool { return p.First > 50 || p.Third > 50 }
+
+	r := rand.New(rand.NewSource(9))
+	for i := 0; i < 20; i++ {
+		v, s := gen.Generate(r, Size{})
+		if !fails(v) {
+			continue
+		}
+		min := shrinkWhile(v, s, fails, 2000)
+		if !fails(min) {
+			t.Fatalf("Shrunk value %+v no longer fails", min)
+		}
+		if min.First+min.Third != 51 {
+			t.Errorf("Expected one component at 51 and the other at 0, got %+v", min)
+		}
+	}
+}
+
+func TestTuple_NilInterfaceComponent(t *testing.T) {
+	gen := Tuple2(Const[error](nil), Const(1))
+	v, _ := gen.Generate(rand.New(rand.NewSource(1)), Size{})
+	if v.First != nil || v.Second != 1 {
+		t.Errorf("Tuple2() = %+v, expected {nil 1}", v)
+	}
+}
@@ -3,7 +3,10 @@
 // custom generators with shrinking capabilities.
 package gen
 
-import "math/rand"
+import (
+	"math/rand"
+	"sync/atomic"
+)
 
 // Size controls the scale and limits of generators.
 // It defines the minimum and maximum bounds for generated values.
@@ -12,6 +15,23 @@ type Size struct {
 	Min int
 	// Max is the maximum bound for generated values.
 	Max int
+	// Scale is the size hint set by the runner. It grows from small to large
+	// over a run; generators without explicit bounds use it in place of their
+	// default ranges and lengths. Zero means no hint.
+	Scale int
+	// Strategy is the shrinking strategy of the run, ShrinkStrategyBFS or
+	// ShrinkStrategyDFS, set by the runner. Shrinkers read it when the value
+	// is generated, so runs with different strategies do not interfere.
+	// Empty means the default set by SetShrinkStrategy.
+	Strategy string
+}
+
+// scaleOr returns the runner's Scale, or def if no scale was given.
+func (s Size) scaleOr(def int) int {
+	if s.Scale > 0 {
+		return s.Scale
+	}
+	return def
 }
 
 // Shrinker proposes "smaller" candidates during the shrinking process.
@@ -34,24 +54,40 @@ const (
 	ShrinkStrategyDFS = "dfs" // depth-first search
 )
 
-// shrinkStrategy holds the current shrinking strategy.
-// It can be either "bfs" (breadth-first search) or "dfs" (depth-first search).
-var shrinkStrategy = ShrinkStrategyBFS
+// defaultDFS holds the default shrinking strategy: depth-first when set,
+// breadth-first otherwise.
+var defaultDFS atomic.Bool
 
-// SetShrinkStrategy sets the shrinking strategy for all generators.
-// Valid strategies are "dfs" (depth-first search) and "bfs" (breadth-first search).
-// Any other value defaults to "bfs".
+// SetShrinkStrategy sets the default shrinking strategy, used by generators
+// whose Size has no Strategy. Valid strategies are "dfs" (depth-first search)
+// and "bfs" (breadth-first search). Any other value defaults to "bfs".
 func SetShrinkStrategy(s string) {
-	if s == ShrinkStrategyDFS {
-		shrinkStrategy = ShrinkStrategyDFS
-	} else {
-		shrinkStrategy = ShrinkStrategyBFS
-	}
+	defaultDFS.Store(s == ShrinkStrategyDFS)
 }
 
-// GetShrinkStrategy returns the current shrinking strategy.
+// GetShrinkStrategy returns the default shrinking strategy.
 func GetShrinkStrategy() string {
-	return shrinkStrategy
+	if defaultDFS.Load() {
+		return ShrinkStrategyDFS
+	}
+	return ShrinkStrategyBFS
+}
+
+// DFS reports whether shrinkers of values generated with s pop candidates
+// depth-first: Strategy if set, the default strategy otherwise. Custom
+// generators with their own shrinkers should use it to follow the strategy
+// of the run.
+func (s Size) DFS() bool {
+	if s.Strategy != "" {
+		return s.Strategy == ShrinkStrategyDFS
+	}
+	return defaultDFS.Load()
+}
+
+// inner returns the Size passed to the generator of a component (an element
+// or field) of a value: the runner's Scale and Strategy, without bounds.
+func (s Size) inner() Size {
+	return Size{Scale: s.Scale, Strategy: s.Strategy}
 }
 
 // T is an optional alias for Generator[T] for compatibility.
@@ -2,6 +2,7 @@ package gen
 
 import (
 	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -36,6 +37,69 @@ func TestSetShrinkStrategy(t *testing.T) {
 	}
 }
 
+func TestSize_Strategy(t *testing.T) {
+	SetShrinkStrategy(ShrinkStrategyDFS)
+	defer SetShrinkStrategy(ShrinkStrategyBFS)
+
+	tests := []struct {
+		strategy string
+		dfs      bool
+	}{
+		{"", true}, // the default
+		{ShrinkStrategyDFS, true},
+		{ShrinkStrategyBFS, false},
+		{"invalid", false},
+	}
+	for _, tt := range tests {
+		if got := (Size{Strategy: tt.strategy}).DFS(); got != tt.dfs {
+			t.Errorf("Size{Strategy: %q}.DFS() = %v, expected %v", tt.strategy, got, tt.dfs)
+		}
+	}
+
+	inner := Size{Min: 1, Max: 5, Scale: 7, Strategy: ShrinkStrategyDFS}.inner()
+	if inner != (Size{Scale: 7, Strategy: ShrinkStrategyDFS}) {
+		t.Errorf("Size.inner() = %+v, expected the scale and strategy only", inner)
+	}
+}
+
+// candidates returns the first n shrink candidates of the value generated by
+// g from seed, accepting none of them.
+func candidates[T any](g Generator[T], seed int64, sz Size, n int) []T {
+	_, shrink := g.Generate(rand.New(rand.NewSource(seed)), sz)
+	var out []T
+	for len(out) < n {
+		v, ok := shrink(false)
+		if !ok {
+			break
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestSize_StrategyOverridesDefault(t *testing.T) {
+	g := SliceOf(IntRange(0, 1000), Size{Min: 4, Max: 8})
+
+	SetShrinkStrategy(ShrinkStrategyBFS)
+	bfs := candidates(g, 1, Size{}, 5)
+	SetShrinkStrategy(ShrinkStrategyDFS)
+	dfs := candidates(g, 1, Size{}, 5)
+	// the strategy of the Size wins over the default
+	perRunBFS := candidates(g, 1, Size{Strategy: ShrinkStrategyBFS}, 5)
+	SetShrinkStrategy(ShrinkStrategyBFS)
+	perRunDFS := candidates(g, 1, Size{Strategy: ShrinkStrategyDFS}, 5)
+
+	if reflect.DeepEqual(bfs, dfs) {
+		t.Fatalf("Expected BFS and DFS to propose different candidates, got %v", bfs)
+	}
+	if !reflect.DeepEqual(perRunBFS, bfs) {
+		t.Errorf("Expected Size{Strategy: bfs} to shrink like BFS, got %v, expected %v", perRunBFS, bfs)
+	}
+	if !reflect.DeepEqual(perRunDFS, dfs) {
+		t.Errorf("Expected Size{Strategy: dfs} to shrink like DFS, got %v, expected %v", perRunDFS, dfs)
+	}
+}
+
 func TestGenFunc(t *testing.T) {
 	expected := 42
 	gen := GenFunc[int]{
@@ -67,3 +131,12 @@ func TestFrom(t *testing.T) {
 		t.Errorf("From().Generate() = %q, expected %q", value, expected)
 	}
 }
+
+func TestSizeScaleOr(t *testing.T) {
+	if got := (Size{}).scaleOr(16); got != 16 {
+		t.Errorf("Size{}.scaleOr(16) = %d, expected 16", got)
+	}
+	if got := (Size{Scale: 3}).scaleOr(16); got != 3 {
+		t.Errorf("Size{Scale: 3}.scaleOr(16) = %d, expected 3", got)
+	}
+}
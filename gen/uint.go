@@ -16,7 +16,7 @@ func Uint(size Size) Generator[uint] {
 			min, max = max, min
 		}
 		v := min + uint(r.Intn(int(max-min+1))) // #nosec G115 -- Safe for property-based testing ranges
-		return unsignedShrinkInit(v, min, max)
+		return unsignedShrinkInit(v, min, max, sz.DFS())
 	})
 }
 
@@ -25,12 +25,12 @@ func UintRange(min, max uint) Generator[uint] {
 	if min > max {
 		min, max = max, min
 	}
-	return From(func(r *rand.Rand, _ Size) (uint, Shrinker[uint]) {
+	return From(func(r *rand.Rand, sz Size) (uint, Shrinker[uint]) {
 		if r == nil {
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
 		}
 		v := min + uint(r.Intn(int(max-min+1))) // #nosec G115 -- Safe for property-based testing ranges
-		return unsignedShrinkInit(v, min, max)
+		return unsignedShrinkInit(v, min, max, sz.DFS())
 	})
 }
 
@@ -39,6 +39,6 @@ func UintRange(min, max uint) Generator[uint] {
 // uintShrinkInit initializes the shrinking process for a uint value.
 // It returns the initial value and a shrinker function that can generate
 // progressively smaller candidates.
-func uintShrinkInit(start, min, max uint) (uint, Shrinker[uint]) {
-	return unsignedShrinkInit(start, min, max)
+func uintShrinkInit(start, min, max uint, dfs bool) (uint, Shrinker[uint]) {
+	return unsignedShrinkInit(start, min, max, dfs)
 }
@@ -14,7 +14,7 @@ func Uint64(size Size) Generator[uint64] {
 			min, max = max, min
 		}
 		v := min + uint64(r.Intn(int(max-min+1))) // #nosec G115 -- Safe for property-based testing ranges
-		return unsignedShrinkInit(v, min, max)
+		return unsignedShrinkInit(v, min, max, sz.DFS())
 	})
 }
 
@@ -23,12 +23,12 @@ func Uint64Range(min, max uint64) Generator[uint64] {
 	if min > max {
 		min, max = max, min
 	}
-	return From(func(r *rand.Rand, _ Size) (uint64, Shrinker[uint64]) {
+	return From(func(r *rand.Rand, sz Size) (uint64, Shrinker[uint64]) {
 		if r == nil {
 			r = rand.New(rand.NewSource(rand.Int63())) // #nosec G404 -- Using math/rand for deterministic property-based testing
 		}
 		v := min + uint64(r.Intn(int(max-min+1))) // #nosec G115 -- Safe for property-based testing ranges
-		return unsignedShrinkInit(v, min, max)
+		return unsignedShrinkInit(v, min, max, sz.DFS())
 	})
 }
 
@@ -37,13 +37,13 @@ func Uint64Range(min, max uint64) Generator[uint64] {
 // uint64ShrinkInit initializes the shrinking process for a uint64 value.
 // It returns the initial value and a shrinker function that can generate
 // progressively smaller candidates.
-func uint64ShrinkInit(start, min, max uint64) (uint64, Shrinker[uint64]) {
-	return unsignedShrinkInit(start, min, max)
+func uint64ShrinkInit(start, min, max uint64, dfs bool) (uint64, Shrinker[uint64]) {
+	return unsignedShrinkInit(start, min, max, dfs)
 }
 
 // autoRangeUint64 decides the final range for Uint64(...) by combining the local "size" and the
 // "size" coming from the runner. We prefer the largest range informed; if nothing is
-// informed, we use [0, 100].
+// informed, we use [0, Scale] with the runner's Scale, or [0, 100] without one.
 func autoRangeUint64(local, fromRunner Size) (uint64, uint64) {
 	return autoRangeUnsigned[uint64](local, fromRunner)
 }
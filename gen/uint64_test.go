@@ -6,7 +6,7 @@ import (
 )
 
 func TestUint64ShrinkerWithAccept(t *testing.T) {
-	_, shrink := uint64ShrinkInit(50, 0, 100)
+	_, shrink := uint64ShrinkInit(50, 0, 100, false)
 
 	next1, ok1 := shrink(false)
 	if !ok1 {
@@ -25,7 +25,7 @@ func TestUint64ShrinkerWithAccept(t *testing.T) {
 
 func TestUint64ShrinkerExhaustion(t *testing.T) {
 	// Test shrinking behavior until exhaustion
-	_, shrink := uint64ShrinkInit(50, 0, 100)
+	_, shrink := uint64ShrinkInit(50, 0, 100, false)
 
 	callCount := 0
 	for {
@@ -49,7 +49,7 @@ func TestUint64ShrinkerWithDFSSStrategy(t *testing.T) {
 	SetShrinkStrategy(ShrinkStrategyDFS)
 	defer SetShrinkStrategy(ShrinkStrategyBFS)
 
-	_, shrink := uint64ShrinkInit(50, 0, 100)
+	_, shrink := uint64ShrinkInit(50, 0, 100, true)
 
 	next, ok := shrink(false)
 	if !ok {
@@ -78,7 +78,7 @@ func TestUint64ShrinkerEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			start, shrink := uint64ShrinkInit(tt.start, tt.min, tt.max)
+			start, shrink := uint64ShrinkInit(tt.start, tt.min, tt.max, false)
 
 			if start != tt.start {
 				t.Errorf("uint64ShrinkInit() start = %d, expected %d", start, tt.start)
@@ -183,7 +183,7 @@ func TestUint64RangeWithRunnerSize(t *testing.T) {
 }
 
 func TestUint64ShrinkingTarget(t *testing.T) {
-	_, shrink := uint64ShrinkInit(100, 0, 200)
+	_, shrink := uint64ShrinkInit(100, 0, 200, false)
 
 	zeroFound := false
 	for i := 0; i < 20; i++ {
@@ -203,7 +203,7 @@ func TestUint64ShrinkingTarget(t *testing.T) {
 }
 
 func TestUint64ShrinkingBisection(t *testing.T) {
-	_, shrink := uint64ShrinkInit(100, 0, 200)
+	_, shrink := uint64ShrinkInit(100, 0, 200, false)
 
 	halfFound := false
 	for i := 0; i < 10; i++ {
@@ -224,7 +224,7 @@ func TestUint64ShrinkingBisection(t *testing.T) {
 }
 
 func TestUint64ShrinkingUnitStep(t *testing.T) {
-	_, shrink := uint64ShrinkInit(5, 0, 10)
+	_, shrink := uint64ShrinkInit(5, 0, 10, false)
 
 	unitStepFound := false
 	for i := 0; i < 10; i++ {
@@ -245,7 +245,7 @@ func TestUint64ShrinkingUnitStep(t *testing.T) {
 }
 
 func TestUint64ShrinkingBoundaries(t *testing.T) {
-	_, shrink := uint64ShrinkInit(50, 0, 100)
+	_, shrink := uint64ShrinkInit(50, 0, 100, false)
 
 	minFound := false
 	maxFound := false
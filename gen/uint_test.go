@@ -41,7 +41,7 @@ func TestUintRange(t *testing.T) {
 
 func TestUintShrinker(t *testing.T) {
 	// Test uint shrinking behavior
-	start, shrink := uintShrinkInit(50, 0, 100)
+	start, shrink := uintShrinkInit(50, 0, 100, false)
 
 	if start != 50 {
 		t.Errorf("uintShrinkInit() start = %d, expected 50", start)
@@ -99,7 +99,7 @@ func TestUint64Range(t *testing.T) {
 
 func TestUint64Shrinker(t *testing.T) {
 	// Test uint64 shrinking behavior
-	start, shrink := uint64ShrinkInit(50, 0, 100)
+	start, shrink := uint64ShrinkInit(50, 0, 100, false)
 
 	if start != 50 {
 		t.Errorf("uint64ShrinkInit() start = %d, expected 50", start)
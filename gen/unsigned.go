@@ -2,7 +2,7 @@ package gen
 
 // unsignedShrinkInit is a generic implementation for unsigned integer shrinking.
 // It works with any unsigned integer type that supports the required operations.
-func unsignedShrinkInit[T ~uint | ~uint64](start, min, max T) (T, Shrinker[T]) {
+func unsignedShrinkInit[T ~uint | ~uint64](start, min, max T, dfs bool) (T, Shrinker[T]) {
 	cur, last := clampUnsigned(start, min, max), clampUnsigned(start, min, max)
 
 	queue := make([]T, 0, 16)
@@ -55,7 +55,7 @@ func unsignedShrinkInit[T ~uint | ~uint64](start, min, max T) (T, Shrinker[T]) {
 		if len(queue) == 0 {
 			return 0, false
 		}
-		if shrinkStrategy == ShrinkStrategyDFS {
+		if dfs {
 			v := queue[len(queue)-1]
 			queue = queue[:len(queue)-1]
 			return v, true
@@ -92,7 +92,7 @@ func clampUnsigned[T ~uint | ~uint64](x, min, max T) T {
 
 // autoRangeUnsigned decides the final range for unsigned integers by combining the local "size" and the
 // "size" coming from the runner. We prefer the largest range informed; if nothing is
-// informed, we use [0, 100].
+// informed, we use [0, Scale] with the runner's Scale, or [0, 100] without one.
 func autoRangeUnsigned[T ~uint | ~uint64](local, fromRunner Size) (T, T) {
 	M := 0
 	for _, s := range []Size{local, fromRunner} {
@@ -101,7 +101,7 @@ func autoRangeUnsigned[T ~uint | ~uint64](local, fromRunner Size) (T, T) {
 		}
 	}
 	if M == 0 {
-		M = 100
+		M = fromRunner.scaleOr(100)
 	}
 	return 0, T(M)
 }
@@ -0,0 +1,151 @@
+package prop
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Classify labels the current example with label when cond is true. At the end
+// of the run, ForAll logs how many examples received each label. A label counts
+// once per example, however many times it is applied. Outside the examples of
+// a property run (e.g. under Fuzz) it does nothing.
+func Classify(t *testing.T, label string, cond bool) {
+	if cond {
+		if ex := exampleOf(t); ex != nil {
+			ex.labels[label] = struct{}{}
+		}
+	}
+}
+
+// Collect labels the current example with the value, formatted with %v.
+func Collect(t *testing.T, value any) {
+	Classify(t, fmt.Sprint(value), true)
+}
+
+// Cover is Classify with a coverage requirement: the property fails at the end
+// of the run if less than minPercent of the examples received label.
+func Cover(t *testing.T, label string, minPercent float64, cond bool) {
+	if ex := exampleOf(t); ex != nil {
+		ex.required[label] = max(ex.required[label], minPercent)
+	}
+	Classify(t, label, cond)
+}
+
+// coverage accumulates the labels of the examples of a run.
+type coverage struct {
+	mu       sync.Mutex
+	examples int
+	counts   map[string]int
+	required map[string]float64
+}
+
+// exampleLabels holds the labels applied during one example.
+type exampleLabels struct {
+	labels   map[string]struct{}
+	required map[string]float64
+}
+
+var (
+	examplesMu sync.Mutex
+	examples   = map[*testing.T]*exampleLabels{}
+)
+
+// exampleOf returns the labels of the example running as t, or nil.
+func exampleOf(t *testing.T) *exampleLabels {
+	examplesMu.Lock()
+	defer examplesMu.Unlock()
+	return examples[t]
+}
+
+// newCoverage returns an empty coverage.
+func newCoverage() *coverage {
+	return &coverage{counts: map[string]int{}, required: map[string]float64{}}
+}
+
+// track wraps body so the labels of every example it runs are counted.
+func track[T any](cov *coverage, body func(*testing.T, T)) func(*testing.T, T) {
+	return func(t *testing.T, v T) {
+		ex := &exampleLabels{labels: map[string]struct{}{}, required: map[string]float64{}}
+		examplesMu.Lock()
+		examples[t] = ex
+		examplesMu.Unlock()
+
+		defer func() {
+			examplesMu.Lock()
+			delete(examples, t)
+			examplesMu.Unlock()
+
+			cov.mu.Lock()
+			defer cov.mu.Unlock()
+			cov.examples++
+			for label := range ex.labels {
+				cov.counts[label]++
+			}
+			for label, p := range ex.required {
+				cov.required[label] = max(cov.required[label], p)
+				if _, ok := cov.counts[label]; !ok {
+					cov.counts[label] = 0
+				}
+			}
+		}()
+		body(t, v)
+	}
+}
+
+// report logs the label distribution of the run and, if checkCover is set,
+// fails t for every coverage requirement that was not met. It does nothing if
+// no label was used.
+func (c *coverage) report(t *testing.T, checkCover bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.counts) == 0 || c.examples == 0 {
+		return
+	}
+
+	labels := c.labels()
+	t.Log(c.table(labels))
+	if !checkCover {
+		return
+	}
+	for _, label := range labels {
+		if p, ok := c.required[label]; ok && c.percent(label) < p {
+			t.Errorf("[rapidx] insufficient coverage: %q in %.1f%% of examples, required %.1f%%", label, c.percent(label), p)
+		}
+	}
+}
+
+// labels returns the labels of the run, most frequent first.
+func (c *coverage) labels() []string {
+	labels := make([]string, 0, len(c.counts))
+	for label := range c.counts {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if c.counts[labels[i]] != c.counts[labels[j]] {
+			return c.counts[labels[i]] > c.counts[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+	return labels
+}
+
+// table formats the distribution of labels, one per line.
+func (c *coverage) table(labels []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[rapidx] label distribution (%d examples):", c.examples)
+	for _, label := range labels {
+		fmt.Fprintf(&b, "\n  %5.1f%% %s", c.percent(label), label)
+		if p, ok := c.required[label]; ok {
+			fmt.Fprintf(&b, " (cover %.1f%%)", p)
+		}
+	}
+	return b.String()
+}
+
+// percent returns the percentage of examples that received label.
+func (c *coverage) percent(label string) float64 {
+	return 100 * float64(c.counts[label]) / float64(c.examples)
+}
@@ -0,0 +1,150 @@
+package prop
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// runExamples runs body as a tracked property over vals, one example each.
+func runExamples(cov *coverage, vals []int, body func(*testing.T, int)) {
+	tracked := track(cov, body)
+	for _, v := range vals {
+		tracked(&testing.T{}, v)
+	}
+}
+
+// helperEnv names the test that runHelper runs as a helper in a subprocess.
+const helperEnv = "RAPIDX_HELPER"
+
+// runHelper runs the test of t again in a subprocess, as a helper whose
+// failures are expected, and returns its output.
+func runHelper(t *testing.T) string {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^"+t.Name()+"$", "-test.v")
+	cmd.Env = append(os.Environ(), helperEnv+"="+t.Name())
+	out, _ := cmd.CombinedOutput()
+	return string(out)
+}
+
+// isHelper reports whether t runs as the helper of runHelper.
+func isHelper(t *testing.T) bool {
+	return os.Getenv(helperEnv) == t.Name()
+}
+
+// TestClassify_Counts tests that labels are counted once per example.
+func TestClassify_Counts(t *testing.T) {
+	cov := newCoverage()
+	runExamples(cov, []int{0, 1, 2, 3, -4}, func(t *testing.T, x int) {
+		Classify(t, "even", x%2 == 0)
+		Classify(t, "even", x%2 == 0) // counted once
+		Classify(t, "negative", x < 0)
+		Collect(t, x%2 == 0)
+	})
+
+	if cov.examples != 5 {
+		t.Errorf("Expected 5 examples, got %d", cov.examples)
+	}
+	want := map[string]int{"even": 3, "negative": 1, "true": 3, "false": 2}
+	for label, n := range want {
+		if cov.counts[label] != n {
+			t.Errorf("Expected %q in %d examples, got %d", label, n, cov.counts[label])
+		}
+	}
+	if len(cov.counts) != len(want) {
+		t.Errorf("Expected labels %v, got %v", want, cov.counts)
+	}
+}
+
+// TestClassify_Table tests the distribution table.
+func TestClassify_Table(t *testing.T) {
+	cov := newCoverage()
+	runExamples(cov, []int{1, 2, 3, 4}, func(t *testing.T, x int) {
+		Classify(t, "small", x < 4)
+		Cover(t, "even", 25, x%2 == 0)
+	})
+
+	want := "[rapidx] label distribution (4 examples):" +
+		"\n   75.0% small" +
+		"\n   50.0% even (cover 25.0%)"
+	if got := cov.table(cov.labels()); got != want {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+// TestCover_Requirement tests that unmet coverage fails the run.
+func TestCover_Requirement(t *testing.T) {
+	run := func(minPercent float64, checkCover bool) bool {
+		cov := newCoverage()
+		runExamples(cov, []int{1, 2, 3, 4}, func(t *testing.T, x int) {
+			Cover(t, "big", minPercent, x > 100)
+		})
+		return runCaptured(func(t *testing.T) { cov.report(t, checkCover) })
+	}
+
+	if !run(10, true) {
+		t.Error("Expected a label never seen to fail its coverage requirement")
+	}
+	if run(0, true) {
+		t.Error("Expected a 0% requirement to pass")
+	}
+	if run(10, false) {
+		t.Error("Expected coverage not to be checked when disabled")
+	}
+}
+
+// TestClassify_OutsideRun tests that the helpers do nothing outside a run.
+func TestClassify_OutsideRun(t *testing.T) {
+	Classify(t, "label", true)
+	Collect(t, 1)
+	Cover(t, "label", 100, false)
+}
+
+// TestForAll_Cover tests coverage requirements through ForAll.
+func TestForAll_Cover(t *testing.T) {
+	cfg := Config{Seed: 1, Examples: 200, MaxShrink: 10, StopOnFirstFailure: true}
+	ForAll(t, cfg, gen.IntRange(-100, 100))(func(t *testing.T, x int) {
+		Cover(t, "negative", 20, x < 0)
+		Cover(t, "positive", 20, x > 0)
+		Collect(t, x == 0)
+	})
+}
+
+// TestClassify_ExamplesWithShrinking tests that a failing run with shrinking
+// counts only its examples, sequentially and in parallel. The failing run is
+// a helper in a subprocess, which prints the counts.
+func TestClassify_ExamplesWithShrinking(t *testing.T) {
+	if isHelper(t) {
+		for _, parallelism := range []int{1, 4} {
+			cfg := Config{Seed: 1, Examples: 10, MaxShrink: 50, ShrinkStrat: "bfs", Parallelism: parallelism}
+			body := func(t *testing.T, x int) {
+				Classify(t, "large", x > 500)
+				if x > 500 {
+					t.Errorf("too large: %d", x)
+				}
+			}
+			cov := newCoverage()
+			if parallelism <= 1 {
+				runSequential(t, cfg, gen.IntRange(0, 1000), body, cov, describeValue[int], cfg.Seed)
+			} else {
+				runParallel(t, cfg, gen.IntRange(0, 1000), body, cov, describeValue[int], cfg.Seed)
+			}
+			fmt.Printf("parallelism=%d examples=%d\n", parallelism, cov.examples)
+		}
+		return
+	}
+
+	out := runHelper(t)
+	if !strings.Contains(out, "too large") {
+		t.Fatalf("Expected the helper run to fail, got:\n%s", out)
+	}
+	for _, want := range []string{"parallelism=1 examples=10", "parallelism=4 examples=10"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q, got:\n%s", want, out)
+		}
+	}
+}
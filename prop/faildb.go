@@ -0,0 +1,172 @@
+package prop
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// failureFileExt is the extension of the files stored in the failure database.
+const failureFileExt = ".json"
+
+// failureDB persists minimized counterexamples on disk so that they can be
+// replayed on later runs, much like the corpus kept by `go test -fuzz`.
+//
+// Layout: <dir>/<TestName>/<SubTest>/<hash>.json, one file per counterexample.
+// Values are serialized with encoding/json, so generated types can customize
+// their representation by implementing json.Marshaler and json.Unmarshaler.
+type failureDB struct {
+	dir string
+}
+
+// storedFailure is a counterexample loaded back from the failure database.
+type storedFailure[T any] struct {
+	// path is the file the counterexample was read from.
+	path string
+
+	// value is the decoded counterexample.
+	value T
+}
+
+// newFailureDB returns a failure database rooted at dir.
+// An empty dir disables persistence and yields nil.
+func newFailureDB(dir string) *failureDB {
+	if dir == "" {
+		return nil
+	}
+	return &failureDB{dir: dir}
+}
+
+// testDir returns the directory holding the counterexamples of a test.
+// Subtest names become nested directories.
+func (db *failureDB) testDir(testName string) string {
+	parts := []string{db.dir}
+	for _, p := range strings.Split(testName, "/") {
+		parts = append(parts, sanitizePathSegment(p))
+	}
+	return filepath.Join(parts...)
+}
+
+// save encodes v and writes it under the directory of testName.
+// The file name is derived from the encoded content, so saving the same
+// counterexample twice keeps a single file.
+func (db *failureDB) save(testName string, v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode counterexample: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	dir := db.testDir(testName)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, hex.EncodeToString(sum[:8])+failureFileExt)
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// loadFailures reads every counterexample stored for testName, sorted by file name.
+// Files that cannot be read or decoded into T are reported as errors and skipped.
+func loadFailures[T any](db *failureDB, testName string) ([]storedFailure[T], []error) {
+	dir := db.testDir(testName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == failureFileExt {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var out []storedFailure[T]
+	var errs []error
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path) // #nosec G304 -- Path built from the configured failure directory
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			errs = append(errs, fmt.Errorf("decode %s: %w", path, err))
+			continue
+		}
+		out = append(out, storedFailure[T]{path: path, value: v})
+	}
+	return out, errs
+}
+
+// sanitizePathSegment replaces characters that are not safe in file names.
+func sanitizePathSegment(s string) string {
+	if s == "" || s == "." || s == ".." {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '-', r == '_', r == '.', r == '#':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// replayFailures runs the body against every counterexample stored for the
+// current test before any random example is generated. It reports a failure
+// with t.Fatalf as soon as a stored counterexample still fails.
+func replayFailures[T any](t *testing.T, cfg Config, body func(*testing.T, T), describe func(T) string) {
+	db := newFailureDB(cfg.FailureDir)
+	if db == nil {
+		return
+	}
+
+	stored, errs := loadFailures[T](db, t.Name())
+	for _, err := range errs {
+		t.Logf("[rapidx] skipping stored counterexample: %v", err)
+	}
+
+	for _, sf := range stored {
+		name := "replay#" + strings.TrimSuffix(filepath.Base(sf.path), failureFileExt)
+		passed := t.Run(name, func(st *testing.T) { runExample(st, cfg.Timeout, body, sf.value) })
+		if passed {
+			continue
+		}
+		full := fmt.Sprintf("^%s$/%s(/|$)", t.Name(), name)
+		t.Fatalf("[rapidx] stored counterexample still fails; file=%s\n"+
+			"counterexample (min): %s\nreplay: go test -run '%s'",
+			sf.path, describe(sf.value), full)
+	}
+}
+
+// persistFailure stores the minimized counterexample of the current test and
+// returns a note to append to the failure report (empty when nothing was stored).
+func persistFailure(t *testing.T, cfg Config, min any) string {
+	db := newFailureDB(cfg.FailureDir)
+	if db == nil {
+		return ""
+	}
+	path, err := db.save(t.Name(), min)
+	if err != nil {
+		t.Logf("[rapidx] could not store counterexample: %v", err)
+		return ""
+	}
+	return "\nstored: " + path
+}
@@ -0,0 +1,164 @@
+package prop
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// TestFailureDB_SaveAndLoad tests that stored counterexamples round-trip through the database.
+func TestFailureDB_SaveAndLoad(t *testing.T) {
+	db := newFailureDB(t.TempDir())
+
+	first, err := db.save("TestSomething/sub case", []int{3, 1, 2})
+	if err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	second, err := db.save("TestSomething/sub case", []int{3, 1, 2})
+	if err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("saving the same value twice should reuse the file: %q != %q", first, second)
+	}
+	if _, err := db.save("TestSomething/sub case", []int{}); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	stored, errs := loadFailures[[]int](db, "TestSomething/sub case")
+	if len(errs) != 0 {
+		t.Fatalf("loadFailures() errors = %v", errs)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("Expected 2 stored counterexamples, got %d", len(stored))
+	}
+	for _, sf := range stored {
+		if filepath.Dir(sf.path) != db.testDir("TestSomething/sub case") {
+			t.Errorf("Unexpected location %q", sf.path)
+		}
+	}
+}
+
+// TestFailureDB_LoadMissing tests that a test without stored counterexamples loads nothing.
+func TestFailureDB_LoadMissing(t *testing.T) {
+	db := newFailureDB(t.TempDir())
+
+	stored, errs := loadFailures[int](db, "TestNothing")
+	if len(stored) != 0 || len(errs) != 0 {
+		t.Errorf("Expected no counterexamples and no errors, got %v and %v", stored, errs)
+	}
+}
+
+// TestFailureDB_LoadUndecodable tests that files of the wrong type are skipped with an error.
+func TestFailureDB_LoadUndecodable(t *testing.T) {
+	db := newFailureDB(t.TempDir())
+
+	if _, err := db.save("TestTyped", "not a number"); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	if _, err := db.save("TestTyped", 7); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	stored, errs := loadFailures[int](db, "TestTyped")
+	if len(stored) != 1 || stored[0].value != 7 {
+		t.Errorf("Expected only the int counterexample, got %v", stored)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 decode error, got %v", errs)
+	}
+}
+
+// TestFailureDB_Disabled tests that an empty directory disables the database.
+func TestFailureDB_Disabled(t *testing.T) {
+	if db := newFailureDB(""); db != nil {
+		t.Errorf("Expected nil database for empty directory, got %v", db)
+	}
+	if note := persistFailure(t, Config{}, 1); note != "" {
+		t.Errorf("Expected no note when persistence is disabled, got %q", note)
+	}
+}
+
+// TestSanitizePathSegment tests the conversion of test names into file names.
+func TestSanitizePathSegment(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"TestFoo", "TestFoo"},
+		{"ex#1", "ex#1"},
+		{"with space", "with_space"},
+		{"a:b*c", "a_b_c"},
+		{"..", "_"},
+		{"", "_"},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizePathSegment(tt.in); got != tt.want {
+			t.Errorf("sanitizePathSegment(%q) = %q, expected %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestForAll_ReplaysStoredCounterexamples tests that stored counterexamples run
+// before any randomly generated example.
+func TestForAll_ReplaysStoredCounterexamples(t *testing.T) {
+	dir := t.TempDir()
+	db := newFailureDB(dir)
+	if _, err := db.save(t.Name(), 1234); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	config := Config{
+		Seed:        12345,
+		Examples:    3,
+		MaxShrink:   10,
+		ShrinkStrat: "bfs",
+		Parallelism: 1,
+		FailureDir:  dir,
+	}
+
+	var seen []int
+	ForAll(t, config, gen.IntRange(0, 10))(func(t *testing.T, val int) {
+		seen = append(seen, val)
+	})
+
+	if len(seen) != 4 {
+		t.Fatalf("Expected 1 replayed and 3 generated examples, got %v", seen)
+	}
+	if seen[0] != 1234 {
+		t.Errorf("Expected the stored counterexample to run first, got %v", seen)
+	}
+
+	// Passing replays are kept so they keep guarding against regressions.
+	if _, err := os.Stat(db.testDir(t.Name())); err != nil {
+		t.Errorf("Expected stored counterexamples to be kept: %v", err)
+	}
+}
+
+// TestForAll_FailureDirUnused tests that ForAll does not touch the database
+// when every example passes.
+func TestForAll_FailureDirUnused(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db")
+
+	config := Config{
+		Seed:        12345,
+		Examples:    5,
+		MaxShrink:   10,
+		ShrinkStrat: "bfs",
+		Parallelism: 1,
+		FailureDir:  dir,
+	}
+
+	g := gen.From(func(r *rand.Rand, sz gen.Size) (int, gen.Shrinker[int]) {
+		return 42, func(accept bool) (int, bool) { return 0, false }
+	})
+	ForAll(t, config, g)(func(t *testing.T, val int) {})
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected no failure database to be created, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package prop
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// Fuzz runs a property under Go's native fuzzing engine. Every fuzz input is
+// turned into a random source that consumes its bytes, so any generator can
+// be driven by the coverage-guided mutations of `go test -fuzz`:
+//
+//	func FuzzParse(f *testing.F) {
+//	    prop.Fuzz(f, gen.String(gen.AlphabetASCII, gen.Size{}), func(t *testing.T, s string) {
+//	        // same body as the ForAll property
+//	    })
+//	}
+//
+// Without -fuzz, `go test` runs the seed corpus (an empty input plus anything
+// added with f.Add or stored under testdata/fuzz). A failing input is shrunk
+// with the generator's shrinker before being reported; the fuzzer stores the
+// original input, so replaying it shows the same minimal counterexample.
+//
+// The shrinking strategy, shrink budget, example timeout and maximum size
+// come from Default(), i.e. from the -rapidx.* flags.
+func Fuzz[T any](f *testing.F, g gen.Generator[T], body func(*testing.T, T)) {
+	f.Helper()
+	cfg := Default()
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		val, shrink := g.Generate(bytesRand(data), gen.Size{Scale: cfg.MaxSize, Strategy: cfg.strategy()})
+		var reason string
+		if t.Run("input", func(st *testing.T) { reason = runExample(st, cfg.Timeout, body, val) }) {
+			return
+		}
+
+		min, steps := shrinkFailure(t, cfg, "input", val, reason, shrink, body)
+		t.Fatalf("[rapidx] property failed on fuzz input; input_len=%d; shrunk_steps=%d\n"+
+			"counterexample (min): %s", len(data), steps, describeValue(min))
+	})
+}
+
+// bytesSource is a rand.Source64 that consumes a fuzz input, eight bytes per
+// draw. The first bytes form the high bits, which small draws such as
+// Intn(10) depend on. Once the input is exhausted it yields zeros, which
+// generators map to their simplest values.
+type bytesSource struct {
+	data []byte
+}
+
+// bytesRand returns a *rand.Rand drawing from data.
+func bytesRand(data []byte) *rand.Rand {
+	return rand.New(&bytesSource{data: data}) // #nosec G404 -- Using math/rand for deterministic property-based testing
+}
+
+// Uint64 implements rand.Source64.
+func (s *bytesSource) Uint64() uint64 {
+	var buf [8]byte
+	n := copy(buf[:], s.data)
+	s.data = s.data[n:]
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// Int63 implements rand.Source.
+func (s *bytesSource) Int63() int64 { return int64(s.Uint64() >> 1) }
+
+// Seed implements rand.Source; the input cannot be reseeded.
+func (s *bytesSource) Seed(int64) {}
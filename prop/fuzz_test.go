@@ -0,0 +1,62 @@
+package prop
+
+import (
+	"testing"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+func TestBytesSource(t *testing.T) {
+	src := &bytesSource{data: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}}
+
+	if got := src.Uint64(); got != 0x0102030405060708 {
+		t.Errorf("Uint64() = %#x, expected 0x0102030405060708", got)
+	}
+	// a partial draw is padded with zeros
+	if got := src.Uint64(); got != 0x0900000000000000 {
+		t.Errorf("Uint64() = %#x, expected 0x0900000000000000", got)
+	}
+	if got := src.Int63(); got != 0 {
+		t.Errorf("Int63() on exhausted input = %d, expected 0", got)
+	}
+}
+
+func TestBytesRand_Deterministic(t *testing.T) {
+	data := []byte("some fuzz input that is long enough")
+	g := gen.SliceOf(gen.IntRange(0, 1000), gen.Size{Max: 10})
+
+	a, _ := g.Generate(bytesRand(data), gen.Size{})
+	b, _ := g.Generate(bytesRand(data), gen.Size{})
+	if len(a) != len(b) {
+		t.Fatalf("Same input generated %v and %v", a, b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Same input generated %v and %v", a, b)
+		}
+	}
+}
+
+func TestBytesRand_EmptyInputIsSimplest(t *testing.T) {
+	v, _ := gen.IntRange(5, 50).Generate(bytesRand(nil), gen.Size{})
+	if v != 5 {
+		t.Errorf("IntRange(5, 50) on empty input = %d, expected 5", v)
+	}
+}
+
+func FuzzFuzz_ReverseTwice(f *testing.F) {
+	f.Add([]byte{3, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3})
+	Fuzz(f, gen.SliceOf(gen.Int(gen.Size{}), gen.Size{}), func(t *testing.T, xs []int) {
+		ys := append([]int(nil), xs...)
+		for k := 0; k < 2; k++ {
+			for i, j := 0, len(ys)-1; i < j; i, j = i+1, j-1 {
+				ys[i], ys[j] = ys[j], ys[i]
+			}
+		}
+		for i := range xs {
+			if xs[i] != ys[i] {
+				t.Fatalf("reverse(reverse(%v)) = %v", xs, ys)
+			}
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package prop
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// runExample runs body on v as t, failing t instead of crashing the test
+// binary if body panics: the panic value and its stack trace are reported as
+// a test error. Body runs in its own goroutine, so t.FailNow ends it without
+// ending t. With a positive timeout, the example fails if body has not
+// returned after that long; body keeps running, but is abandoned: once t has
+// completed, reporting to it panics, and those panics are dropped instead of
+// crashing the test binary.
+//
+// It returns how the example failed, to tell failures apart: the site of a
+// panic (panicReason) or the timeout (timeoutReason). It returns "" if the
+// example passed or failed otherwise.
+func runExample[T any](t *testing.T, timeout time.Duration, body func(*testing.T, T), v T) string {
+	var abandoned atomic.Bool
+	var site string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil && !abandoned.Load() {
+				panic(r)
+			}
+		}()
+		site = guard(t, body, v, &abandoned)
+	}()
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		tm := time.NewTimer(timeout)
+		defer tm.Stop()
+		timer = tm.C
+	}
+	select {
+	case <-done:
+	case <-timer:
+		abandoned.Store(true)
+		t.Errorf("[rapidx] example timed out after %v", timeout)
+		return fmt.Sprintf("%s%v", timeoutReason, timeout)
+	}
+
+	if site != "" {
+		return panicReason + site
+	}
+	return ""
+}
+
+// guard runs body on v as t, turning a panic into a test error, unless the
+// example has been abandoned. It returns where the panic was raised (see
+// panicSite), or "" if body did not panic.
+func guard[T any](t *testing.T, body func(*testing.T, T), v T, abandoned *atomic.Bool) (site string) {
+	defer func() {
+		if r := recover(); r != nil {
+			site = panicSite()
+			if !abandoned.Load() {
+				t.Errorf("[rapidx] panic: %v\n%s", r, debug.Stack())
+			}
+		}
+	}()
+	body(t, v)
+	return ""
+}
+
+// failsQuietly runs body on v with an internal *testing.T that is not
+// registered as a subtest, and reports whether it failed: through Error,
+// Fatal, FailNow and the like, by panicking, or by not returning within
+// timeout (when positive). Skipping is not a failure. Nothing the body logs
+// is printed; for panics and timeouts, reason describes the failure as
+// runExample does.
+//
+// The internal T supports reporting and logging, but not t.Run, t.Parallel
+// or t.Deadline, which panic inside the testing package, and it never runs
+// the functions registered with t.Cleanup. Such a body does not fail: reason
+// then starts with unsupportedReason (see unsupported). So does every body
+// if registered cleanups cannot be detected (see cleanups).
+func failsQuietly[T any](body func(*testing.T, T), v T, timeout time.Duration) (failed bool, reason string) {
+	type outcome struct {
+		failed bool
+		reason string
+	}
+	st := &testing.T{}
+	if _, ok := cleanups(st); !ok {
+		return false, unsupportedReason + "its cleanups cannot be detected"
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		// a failing body ends with runtime.Goexit (FailNow) or a panic
+		defer func() {
+			r := recover()
+			switch {
+			case hasCleanups(st):
+				done <- outcome{false, unsupportedReason + "it called t.Cleanup"}
+			case r != nil && raisedByTesting():
+				done <- outcome{false, fmt.Sprintf("%s%v", unsupportedReason, r)}
+			case r != nil:
+				done <- outcome{true, panicReason + panicSite()}
+			default:
+				done <- outcome{st.Failed(), ""}
+			}
+		}()
+		body(st, v)
+	}()
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	select {
+	case o := <-done:
+		return o.failed, o.reason
+	case <-timer:
+		return true, fmt.Sprintf("%s%v", timeoutReason, timeout)
+	}
+}
+
+// Prefixes of the failure reasons returned by runExample and failsQuietly.
+const (
+	panicReason       = "panic at "
+	timeoutReason     = "timed out after "
+	unsupportedReason = "the internal T cannot run the body: "
+)
+
+// unsupported reports whether reason, returned by failsQuietly, says that the
+// body needs a registered test to run.
+func unsupported(reason string) bool {
+	return strings.HasPrefix(reason, unsupportedReason)
+}
+
+// sameKind reports whether two failure reasons are of the same kind: both
+// panics, both timeouts, or both ordinary failures.
+func sameKind(a, b string) bool {
+	kind := func(reason string) string {
+		for _, prefix := range []string{panicReason, timeoutReason} {
+			if strings.HasPrefix(reason, prefix) {
+				return prefix
+			}
+		}
+		return ""
+	}
+	return kind(a) == kind(b)
+}
+
+// raisedByTesting reports whether the panic being recovered was raised in
+// the testing package, such as the nil dereference of t.Run on the internal
+// T, rather than by the body. It must be called by the deferred function
+// that recovers.
+func raisedByTesting() bool {
+	frame, ok := panicFrame(false)
+	return ok && strings.HasPrefix(frame.Function, "testing.")
+}
+
+// panicSite describes where the panic being recovered was raised in the code
+// under test: the function and line of the first frame below the panic
+// outside the runtime and the standard library, so that a nil dereference
+// in a test is not confused with every other one. It must be called by the
+// deferred function that recovers.
+func panicSite() string {
+	frame, ok := panicFrame(true)
+	if !ok {
+		return "unknown site"
+	}
+	return fmt.Sprintf("%s (%s:%d)", frame.Function, filepath.Base(frame.File), frame.Line)
+}
+
+// panicFrame returns the frame that raised the panic being recovered: the
+// first one below runtime.gopanic outside the runtime and, if user is set,
+// outside the standard library. It reports false if there is none.
+func panicFrame(user bool) (runtime.Frame, bool) {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			panicking = true
+		case panicking && !strings.HasPrefix(frame.Function, "runtime.") && !(user && inGoroot(frame.File)):
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// goroot is the directory holding the sources of the standard library, as
+// recorded in the binary, found from the file of a function of package
+// strings. It is empty if the file does not tell it.
+var goroot = sync.OnceValue(func() string {
+	pc := reflect.ValueOf(strings.Cut).Pointer()
+	file, _ := runtime.FuncForPC(pc).FileLine(pc)
+	dir, ok := strings.CutSuffix(filepath.ToSlash(filepath.Dir(file)), "/strings")
+	if !ok {
+		return ""
+	}
+	return dir + "/"
+})
+
+// inGoroot reports whether file belongs to the standard library.
+func inGoroot(file string) bool {
+	root := goroot()
+	return root != "" && strings.HasPrefix(filepath.ToSlash(file), root)
+}
+
+// hasCleanups reports whether functions were registered with t.Cleanup on
+// st, which failsQuietly has checked can be detected.
+func hasCleanups(st *testing.T) bool {
+	n, _ := cleanups(st)
+	return n > 0
+}
+
+// cleanups returns the number of functions registered with t.Cleanup on st.
+// The testing package keeps them in an unexported field; ok is false if that
+// field cannot be found with the expected type, in which case the internal T
+// is not used at all rather than taken for having no cleanups.
+func cleanups(st *testing.T) (n int, ok bool) {
+	field := reflect.ValueOf(st).Elem().FieldByName("cleanups")
+	if !field.IsValid() || field.Kind() != reflect.Slice || field.Type().Elem().Kind() != reflect.Func {
+		return 0, false
+	}
+	return field.Len(), true
+}
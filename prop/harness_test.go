@@ -0,0 +1,335 @@
+package prop
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// TestFailsQuietly tests that every way of failing is captured by the
+// internal harness, and that passing or skipping is not a failure.
+func TestFailsQuietly(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  func(*testing.T, int)
+		fails bool
+	}{
+		{"pass", func(t *testing.T, v int) { t.Logf("v=%d", v) }, false},
+		{"errorf", func(t *testing.T, v int) { t.Errorf("v=%d", v) }, true},
+		{"fatal", func(t *testing.T, v int) { t.Fatal("boom"); panic("unreachable") }, true},
+		{"failnow", func(t *testing.T, v int) { t.FailNow() }, true},
+		{"panic", func(t *testing.T, v int) { panic("boom") }, true},
+		{"skip", func(t *testing.T, v int) { t.Skip("not applicable") }, false},
+	}
+	for _, tt := range tests {
+		if got, _ := failsQuietly(tt.body, 1, 0); got != tt.fails {
+			t.Errorf("Expected failsQuietly to return %v for %s, got %v", tt.fails, tt.name, got)
+		}
+	}
+}
+
+// TestFailsQuietly_Timeout tests that a body that does not return within the
+// timeout counts as failing.
+func TestFailsQuietly_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	hang := func(t *testing.T, v int) { <-release }
+
+	if failed, reason := failsQuietly(hang, 1, 10*time.Millisecond); !failed || reason != "timed out after 10ms" {
+		t.Errorf("Expected a hanging body to fail after the timeout, got %v (%q)", failed, reason)
+	}
+	if failed, _ := failsQuietly(func(*testing.T, int) {}, 1, time.Second); failed {
+		t.Error("Expected a body returning before the timeout to pass")
+	}
+}
+
+// TestFailsQuietly_Unsupported tests that a body using what the internal T
+// does not support does not fail, and says so in the reason.
+func TestFailsQuietly_Unsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		body func(*testing.T, int)
+	}{
+		{"run", func(t *testing.T, v int) { t.Run("inner", func(*testing.T) {}) }},
+		{"deadline", func(t *testing.T, v int) { t.Deadline() }},
+		{"cleanup", func(t *testing.T, v int) { t.Cleanup(func() {}) }},
+		{"cleanup and fail", func(t *testing.T, v int) { t.Cleanup(func() {}); t.Fatal("boom") }},
+	}
+	for _, tt := range tests {
+		failed, reason := failsQuietly(tt.body, 1, 0)
+		if failed || !unsupported(reason) {
+			t.Errorf("Expected %s to be unsupported, got %v (%q)", tt.name, failed, reason)
+		}
+	}
+
+	// a panic of the body itself is not mistaken for one of the harness
+	var m map[string]int
+	if failed, reason := failsQuietly(func(*testing.T, int) { m["x"] = 1 }, 1, 0); !failed || unsupported(reason) {
+		t.Errorf("Expected a panic of the body to fail, got %v (%q)", failed, reason)
+	}
+}
+
+// TestCleanups tests that the functions registered with t.Cleanup on the
+// internal T can be detected with this version of the testing package, so
+// that failsQuietly does not send every body back to subtests.
+func TestCleanups(t *testing.T) {
+	st := &testing.T{}
+	if n, ok := cleanups(st); !ok || n != 0 {
+		t.Fatalf("Expected no cleanups to be detected, got %d (%v)", n, ok)
+	}
+	st.Cleanup(func() {})
+	if n, ok := cleanups(st); !ok || n != 1 {
+		t.Errorf("Expected 1 cleanup to be detected, got %d (%v)", n, ok)
+	}
+}
+
+// TestRunExample tests that panics and hangs fail the test running the
+// example instead of crashing or blocking it, and that the returned reason
+// tells how it failed.
+func TestRunExample(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		body    func(*testing.T, int)
+		fails   bool
+		reason  string // prefix of the returned reason
+	}{
+		{"pass", 0, func(*testing.T, int) {}, false, ""},
+		{"panic", 0, func(*testing.T, int) { panic("boom") }, true, panicReason},
+		{"panic with timeout", time.Second, func(*testing.T, int) { panic("boom") }, true, panicReason},
+		{"fatal", 0, func(t *testing.T, _ int) { t.Fatal("boom") }, true, ""},
+		{"fatal with timeout", time.Second, func(t *testing.T, _ int) { t.Fatal("boom") }, true, ""},
+		{"hang", 10 * time.Millisecond, func(*testing.T, int) { <-release }, true, timeoutReason},
+	}
+	for _, tt := range tests {
+		var reason string
+		failed := runCaptured(func(t *testing.T) { reason = runExample(t, tt.timeout, tt.body, 1) })
+		if failed != tt.fails {
+			t.Errorf("Expected runExample to fail=%v for %s, got %v", tt.fails, tt.name, failed)
+		}
+		if !strings.HasPrefix(reason, tt.reason) || (tt.reason == "" && strings.HasPrefix(reason, panicReason)) {
+			t.Errorf("Expected a reason starting with %q for %s, got %q", tt.reason, tt.name, reason)
+		}
+	}
+}
+
+// TestRunExample_LateReport tests that a body reporting after its example
+// timed out crashes nothing, and that the example fails only with the
+// timeout. The example is a subtest of a helper in a subprocess, which
+// prints the reason returned by runExample.
+func TestRunExample_LateReport(t *testing.T) {
+	if isHelper(t) {
+		var reason string
+		t.Run("ex", func(st *testing.T) {
+			reason = runExample(st, 20*time.Millisecond, func(t *testing.T, _ int) {
+				time.Sleep(60 * time.Millisecond)
+				t.Errorf("late report")
+			}, 1)
+		})
+		time.Sleep(150 * time.Millisecond) // let the abandoned body report
+		fmt.Printf("reason=%q\n", reason)
+		return
+	}
+
+	out := runHelper(t)
+	if !strings.Contains(out, fmt.Sprintf("reason=%q", timeoutReason+"20ms")) {
+		t.Errorf("Expected the timeout reason, got:\n%s", out)
+	}
+	// the output of the example ends where the late report goes to its parent
+	_, ex, _ := strings.Cut(out, "=== RUN   "+t.Name()+"/ex\n")
+	ex, _, _ = strings.Cut(ex, "===")
+	if ex = strings.TrimSpace(ex); strings.Contains(ex, "\n") || !strings.HasSuffix(ex, ": [rapidx] example timed out after 20ms") {
+		t.Errorf("Expected the example to fail only with the timeout, got:\n%s", out)
+	}
+	if strings.Contains(out, "panic") {
+		t.Errorf("Expected no panic, got:\n%s", out)
+	}
+}
+
+// TestPanicSite tests that a panic is attributed to the code under test,
+// even when raised by the runtime or the standard library.
+func TestPanicSite(t *testing.T) {
+	var m map[string]int
+	n := -1
+	tests := []struct {
+		name string
+		body func(*testing.T, int)
+	}{
+		{"panic", func(*testing.T, int) { panic("boom") }},
+		{"nil map", func(*testing.T, int) { m["x"] = 1 }},
+		{"standard library", func(*testing.T, int) { _ = strings.Repeat("x", n) }},
+	}
+	for _, tt := range tests {
+		_, reason := failsQuietly(tt.body, 1, 0)
+		if !strings.HasPrefix(reason, panicReason+"github.com/lucaskalb/rapidx/prop.TestPanicSite.func") ||
+			!strings.Contains(reason, "(harness_test.go:") {
+			t.Errorf("Expected the %s panic to be raised in TestPanicSite, got %q", tt.name, reason)
+		}
+	}
+}
+
+// TestShrinkFailure_Quiet tests that shrink candidates register no subtest,
+// that a panicking candidate counts as failing, and that only the minimal
+// counterexample is run again as a subtest.
+func TestShrinkFailure_Quiet(t *testing.T) {
+	g := gen.IntRange(0, 1000)
+	val, shrink := g.Generate(exampleRand(1, 0), gen.Size{})
+	if val < 10 {
+		t.Fatalf("Expected seed 1 to generate a failing value, got %d", val)
+	}
+
+	var mu sync.Mutex
+	var subtests []string
+	body := func(st *testing.T, v int) {
+		if st.Name() != "" {
+			// a registered subtest: record it and pass, so the test succeeds
+			mu.Lock()
+			subtests = append(subtests, st.Name())
+			mu.Unlock()
+			return
+		}
+		if v >= 10 {
+			panic("too large")
+		}
+	}
+
+	min, steps := shrinkFailure(t, Config{MaxShrink: 1000}, "ex#1", val, panicReason+"body", shrink, body)
+	if min != 10 {
+		t.Errorf("Expected the panicking candidates to shrink to 10, got %d", min)
+	}
+	if steps == 0 {
+		t.Error("Expected candidates to be tried")
+	}
+	if len(subtests) != 1 || subtests[0] != t.Name()+"/ex#1/min" {
+		t.Errorf("Expected only the ex#1/min subtest, got %v", subtests)
+	}
+}
+
+// TestShrinkFailure_Subtests tests that Config.ShrinkSubtests runs every
+// candidate as a subtest and skips the extra run of the minimal one.
+func TestShrinkFailure_Subtests(t *testing.T) {
+	candidates := []int{3, 2, 1}
+	shrink := func(bool) (int, bool) {
+		if len(candidates) == 0 {
+			return 0, false
+		}
+		v := candidates[0]
+		candidates = candidates[1:]
+		return v, true
+	}
+
+	var subtests []string
+	body := func(st *testing.T, v int) { subtests = append(subtests, st.Name()) }
+
+	_, steps := shrinkFailure(t, Config{MaxShrink: 10, ShrinkSubtests: true}, "ex#1", 4, "", shrink, body)
+	if steps != 3 {
+		t.Errorf("Expected 3 candidates to be tried, got %d", steps)
+	}
+	expected := []string{t.Name() + "/ex#1/shrink#1", t.Name() + "/ex#1/shrink#2", t.Name() + "/ex#1/shrink#3"}
+	if len(subtests) != len(expected) {
+		t.Fatalf("Expected subtests %v, got %v", expected, subtests)
+	}
+	for i := range expected {
+		if subtests[i] != expected[i] {
+			t.Errorf("Expected subtest %q, got %q", expected[i], subtests[i])
+		}
+	}
+}
+
+// TestShrinkFailure_Timeout tests that hanging candidates count as failing,
+// so a hang shrinks toward the smallest input that still hangs.
+func TestShrinkFailure_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	val, shrink := gen.IntRange(0, 1000).Generate(exampleRand(1, 0), gen.Size{})
+	body := func(st *testing.T, v int) {
+		if st.Name() == "" && v >= 10 {
+			<-release
+		}
+	}
+
+	min, _ := shrinkFailure(t, Config{MaxShrink: 1000, Timeout: 5 * time.Millisecond}, "ex#1", val, timeoutReason+"5ms", shrink, body)
+	if min != 10 {
+		t.Errorf("Expected the hanging candidates to shrink to 10, got %d", min)
+	}
+}
+
+// TestShrinkFailure_Duration tests that shrinking stops when its time budget
+// is spent, even if MaxShrink allows more candidates.
+func TestShrinkFailure_Duration(t *testing.T) {
+	shrink := func(bool) (int, bool) { return 1, true } // never exhausted
+	body := func(st *testing.T, v int) {
+		if st.Name() == "" {
+			time.Sleep(time.Millisecond)
+			st.Fail()
+		}
+	}
+
+	cfg := Config{MaxShrink: 1_000_000, ShrinkDuration: 20 * time.Millisecond}
+	start := time.Now()
+	_, steps := shrinkFailure(t, cfg, "ex#1", 2, "", shrink, body)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected shrinking to stop after about 20ms, took %v", elapsed)
+	}
+	if steps == 0 || steps >= cfg.MaxShrink {
+		t.Errorf("Expected the time budget to stop shrinking, tried %d candidates", steps)
+	}
+}
+
+// TestShrinkFailure_SameKind tests that, when collecting every failure, a
+// panic does not shrink into a candidate that fails differently.
+func TestShrinkFailure_SameKind(t *testing.T) {
+	val, shrink := gen.IntRange(0, 1000).Generate(exampleRand(1, 0), gen.Size{})
+	if val < 500 {
+		t.Fatalf("Expected seed 1 to generate a panicking value, got %d", val)
+	}
+	body := func(st *testing.T, v int) {
+		if st.Name() != "" {
+			return
+		}
+		if v >= 500 {
+			panic("too large")
+		}
+		if v%2 == 1 {
+			st.Error("odd")
+		}
+	}
+
+	min, _ := shrinkFailure(t, Config{MaxShrink: 1000}, "ex#1", val, panicReason+"body", shrink, body)
+	if min != 500 {
+		t.Errorf("Expected the panic to shrink to 500, got %d", min)
+	}
+}
+
+// TestShrinkFailure_FallBack tests that a body the internal T cannot run
+// shrinks with subtests instead of accepting every candidate.
+func TestShrinkFailure_FallBack(t *testing.T) {
+	val, shrink := gen.IntRange(0, 1000).Generate(exampleRand(1, 0), gen.Size{})
+	if val <= 500 {
+		t.Fatalf("Expected seed 1 to generate a failing value, got %d", val)
+	}
+
+	var subtests []string
+	body := func(st *testing.T, v int) {
+		st.Run("inner", func(it *testing.T) {
+			// registered subtests pass, so the test succeeds
+			subtests = append(subtests, st.Name())
+		})
+	}
+
+	min, _ := shrinkFailure(t, Config{MaxShrink: 1000}, "ex#1", val, "", shrink, body)
+	if min != val {
+		t.Errorf("Expected no passing candidate to be accepted, got %d", min)
+	}
+	if len(subtests) == 0 || subtests[0] != t.Name()+"/ex#1/shrink#1" {
+		t.Errorf("Expected the candidates to run as subtests, got %v", subtests)
+	}
+}
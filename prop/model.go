@@ -0,0 +1,140 @@
+package prop
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// ModelStateMachine describes a real system tested against a simple model.
+// M is the model state, R the handle of the real system and C the command type.
+//
+// Command sequences are generated and shrunk on the model alone, exactly like
+// a StateMachine; each sequence is then run against a fresh real system and
+// every result is compared to the model's prediction.
+type ModelStateMachine[M, R, C any] struct {
+	// InitialModel is the model state every sequence starts from.
+	InitialModel M
+
+	// Setup creates a fresh real system for a sequence. It may call t.Fatal
+	// if the system cannot be created.
+	Setup func(t *testing.T) R
+
+	// Teardown releases the real system after a sequence. Optional.
+	Teardown func(R)
+
+	// Commands defines the available commands.
+	Commands []ModelCommand[M, R, C]
+}
+
+// ModelCommand is a command of a ModelStateMachine.
+type ModelCommand[M, R, C any] struct {
+	// Name is a human-readable name for the command, unique within the machine.
+	Name string
+
+	// Generator creates instances of the command.
+	Generator gen.Generator[C]
+
+	// StateGenerator, when set, is used instead of Generator and receives the
+	// model state at the step being generated.
+	StateGenerator func(M) gen.Generator[C]
+
+	// Weight is the relative frequency of the command. Zero means 1.
+	Weight float64
+
+	// Precondition determines if the command can be run in the given model state.
+	Precondition func(M, C) bool
+
+	// Next returns the model state after the command. Required.
+	Next func(M, C) M
+
+	// Run executes the command on the real system and returns what was
+	// observed (a value, an error, or both in a struct). Required.
+	Run func(R, C) any
+
+	// Postcondition compares the real result to the model's prediction: it
+	// receives the model state before and after the command and the result
+	// of Run, and returns an error describing any mismatch. Optional.
+	Postcondition func(before M, cmd C, after M, result any) error
+}
+
+// TestModel tests a real system against a model using property-based testing.
+// For every generated sequence, it creates a real system with Setup, runs the
+// commands on it in order, checks each result with the command's
+// Postcondition, and releases the system with Teardown. Failing sequences are
+// shrunk like in TestStateMachine. If Setup registers functions with
+// t.Cleanup, shrinking goes back to subtests after the first candidate (see
+// Config.ShrinkSubtests).
+func TestModel[M, R, C any](t *testing.T, sm ModelStateMachine[M, R, C], cfg Config) {
+	if sm.Setup == nil {
+		panic("prop.TestModel: Setup is required")
+	}
+	for _, cmd := range sm.Commands {
+		if cmd.Next == nil || cmd.Run == nil {
+			panic(fmt.Sprintf("prop.TestModel: command %q needs Next and Run", cmd.Name))
+		}
+	}
+	model := sm.modelMachine()
+	validateStateMachine(model)
+
+	seqGen, cfg := sequenceRun(model, cfg)
+	forAll(t, cfg, seqGen, func(t *testing.T, sequence CommandSequence[C]) {
+		runModelSequence(t, sm, sequence)
+	}, describeSequence[C])
+}
+
+// modelMachine returns the StateMachine over the model alone, used to
+// generate and shrink command sequences.
+func (sm ModelStateMachine[M, R, C]) modelMachine() StateMachine[M, C] {
+	cmds := make([]Command[M, C], len(sm.Commands))
+	for i, mc := range sm.Commands {
+		next := mc.Next
+		cmds[i] = Command[M, C]{
+			Name:           mc.Name,
+			Generator:      mc.Generator,
+			StateGenerator: mc.StateGenerator,
+			Weight:         mc.Weight,
+			Precondition:   mc.Precondition,
+			Execute:        func(m M, c C) (M, error) { return next(m, c), nil },
+		}
+	}
+	return StateMachine[M, C]{InitialState: sm.InitialModel, Commands: cmds}
+}
+
+// findModelCommand returns the command of sm with the given name, or nil.
+func findModelCommand[M, R, C any](sm ModelStateMachine[M, R, C], name string) *ModelCommand[M, R, C] {
+	for i := range sm.Commands {
+		if sm.Commands[i].Name == name {
+			return &sm.Commands[i]
+		}
+	}
+	return nil
+}
+
+// runModelSequence runs sequence against a fresh real system and reports the
+// first result that does not match the model.
+func runModelSequence[M, R, C any](t *testing.T, sm ModelStateMachine[M, R, C], sequence CommandSequence[C]) {
+	sys := sm.Setup(t)
+	if sm.Teardown != nil {
+		defer sm.Teardown(sys)
+	}
+
+	model := sm.InitialModel
+	for i, val := range sequence.Commands {
+		cmd := findModelCommand(sm, stepName(sequence, i))
+		if cmd == nil || (cmd.Precondition != nil && !cmd.Precondition(model, val)) {
+			continue
+		}
+
+		result := cmd.Run(sys, val)
+		after := cmd.Next(model, val)
+		if cmd.Postcondition != nil {
+			if err := cmd.Postcondition(model, val, after, result); err != nil {
+				t.Errorf("step %d (%s %#v): %v", i+1, cmd.Name, val, err)
+				return
+			}
+		}
+		model = after
+	}
+}
@@ -0,0 +1,147 @@
+package prop
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// kvStore is the real system used by the model tests.
+type kvStore struct {
+	data map[int]int
+	// buggy makes Put ignore keys above 50
+	buggy bool
+}
+
+func (s *kvStore) Put(k, v int) {
+	if s.buggy && k > 50 {
+		return
+	}
+	s.data[k] = v
+}
+
+func (s *kvStore) Get(k int) (int, bool) {
+	v, ok := s.data[k]
+	return v, ok
+}
+
+type kvCmd struct {
+	Key, Value int
+}
+
+type kvGet struct {
+	Value int
+	Found bool
+}
+
+// kvMachine returns a model test of kvStore against a map model.
+func kvMachine(buggy bool, setups, teardowns *int) ModelStateMachine[map[int]int, *kvStore, kvCmd] {
+	return ModelStateMachine[map[int]int, *kvStore, kvCmd]{
+		InitialModel: map[int]int{},
+		Setup: func(t *testing.T) *kvStore {
+			*setups++
+			return &kvStore{data: map[int]int{}, buggy: buggy}
+		},
+		Teardown: func(*kvStore) { *teardowns++ },
+		Commands: []ModelCommand[map[int]int, *kvStore, kvCmd]{
+			{
+				Name: "put",
+				Generator: gen.Map(gen.Tuple2(gen.IntRange(0, 100), gen.IntRange(0, 9)), func(p gen.Pair[int, int]) kvCmd {
+					return kvCmd{Key: p.First, Value: p.Second}
+				}),
+				Next: func(m map[int]int, c kvCmd) map[int]int {
+					next := make(map[int]int, len(m)+1)
+					for k, v := range m {
+						next[k] = v
+					}
+					next[c.Key] = c.Value
+					return next
+				},
+				Run: func(s *kvStore, c kvCmd) any {
+					s.Put(c.Key, c.Value)
+					return nil
+				},
+			},
+			{
+				Name:      "get",
+				Generator: gen.Map(gen.IntRange(0, 100), func(k int) kvCmd { return kvCmd{Key: k} }),
+				Next:      func(m map[int]int, _ kvCmd) map[int]int { return m },
+				Run: func(s *kvStore, c kvCmd) any {
+					v, ok := s.Get(c.Key)
+					return kvGet{Value: v, Found: ok}
+				},
+				Postcondition: func(before map[int]int, c kvCmd, _ map[int]int, result any) error {
+					v, ok := before[c.Key]
+					if got := result.(kvGet); got != (kvGet{Value: v, Found: ok}) {
+						return fmt.Errorf("get(%d) = %+v, model has %d, %v", c.Key, got, v, ok)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// TestModel_Agrees tests a correct implementation against the model.
+func TestModel_Agrees(t *testing.T) {
+	setups, teardowns := 0, 0
+	sm := kvMachine(false, &setups, &teardowns)
+
+	TestModel(t, sm, Config{Seed: 1, Examples: 30, MaxShrink: 100, ShrinkStrat: "bfs", Parallelism: 1})
+
+	if setups != 30 || teardowns != 30 {
+		t.Errorf("Expected a fresh system per sequence, got %d setups and %d teardowns", setups, teardowns)
+	}
+}
+
+// TestModel_DetectsMismatch tests that a result differing from the model
+// fails the sequence.
+func TestModel_DetectsMismatch(t *testing.T) {
+	setups, teardowns := 0, 0
+	sm := kvMachine(true, &setups, &teardowns)
+	seq := CommandSequence[kvCmd]{
+		Commands: []kvCmd{{Key: 60, Value: 1}, {Key: 60}},
+		Names:    []string{"put", "get"},
+	}
+
+	if failed := runCaptured(func(t *testing.T) { runModelSequence(t, sm, seq) }); !failed {
+		t.Error("Expected the lost put to be reported")
+	}
+	if setups != 1 || teardowns != 1 {
+		t.Errorf("Expected one setup and one teardown, got %d and %d", setups, teardowns)
+	}
+
+	seq.Commands[0].Key, seq.Commands[1].Key = 10, 10
+	if failed := runCaptured(func(t *testing.T) { runModelSequence(t, sm, seq) }); failed {
+		t.Error("Expected keys below 50 to agree with the model")
+	}
+}
+
+// TestModel_RequiresNextAndRun tests the validation of model commands.
+func TestModel_RequiresNextAndRun(t *testing.T) {
+	sm := ModelStateMachine[int, int, int]{
+		Setup:    func(*testing.T) int { return 0 },
+		Commands: []ModelCommand[int, int, int]{{Name: "incomplete", Generator: gen.Const(1)}},
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected TestModel to panic on a command without Next and Run")
+		}
+	}()
+	TestModel(t, sm, Config{Examples: 1})
+}
+
+// runCaptured runs f with a detached *testing.T and reports whether it failed.
+func runCaptured(f func(t *testing.T)) bool {
+	st := &testing.T{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		f(st)
+	}()
+	wg.Wait()
+	return st.Failed()
+}
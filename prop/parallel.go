@@ -0,0 +1,437 @@
+package prop
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// Limits of the branches of the programs generated by TestModelParallel.
+// Checking a program explores every interleaving of its branches, so branches
+// are kept short; the prefix follows Config.MinSteps and MaxSteps.
+const (
+	parallelBranches     = 2
+	parallelBranchLength = 4
+)
+
+// ParallelProgram is a test case of TestModelParallel: a sequential prefix
+// followed by branches that run concurrently.
+type ParallelProgram[C any] struct {
+	// Prefix runs first, sequentially, to bring the system to a given state.
+	Prefix CommandSequence[C]
+
+	// Branches run concurrently after the prefix, each in its own goroutine.
+	Branches []CommandSequence[C]
+}
+
+// TestModelParallel tests that a concurrent real system is linearizable with
+// respect to its model. Each generated program runs its prefix sequentially
+// on a fresh system, then runs its branches concurrently; the test passes if
+// some interleaving of the branch commands (keeping the order within each
+// branch) explains every observed result, i.e. passes every Postcondition
+// when the model is advanced in that order.
+//
+// Preconditions must hold for every interleaving of the branches; programs
+// are generated and shrunk to keep that true. The real system returned by
+// Setup must be safe for concurrent use. The length of the prefix follows
+// Config.MinSteps and MaxSteps, like the sequences of TestModel, while each
+// branch has at most four commands. Failing programs are shrunk by
+// removing commands, moving branch commands into the prefix and shrinking
+// command values.
+func TestModelParallel[M, R, C any](t *testing.T, sm ModelStateMachine[M, R, C], cfg Config) {
+	if sm.Setup == nil {
+		panic("prop.TestModelParallel: Setup is required")
+	}
+	for _, cmd := range sm.Commands {
+		if cmd.Next == nil || cmd.Run == nil {
+			panic(fmt.Sprintf("prop.TestModelParallel: command %q needs Next and Run", cmd.Name))
+		}
+	}
+	model := sm.modelMachine()
+	validateStateMachine(model)
+
+	seqGen, cfg := sequenceRun(model, cfg)
+	g := parallelProgramGenerator[M, C]{stateMachine: model, prefix: seqGen}
+	forAll(t, cfg, g, func(t *testing.T, p ParallelProgram[C]) {
+		runParallelProgram(t, sm, p)
+	}, describeProgram[C])
+}
+
+// describeProgram formats a parallel program for failure reports.
+func describeProgram[C any](p ParallelProgram[C]) string {
+	section := func(title string, seq CommandSequence[C]) string {
+		if len(seq.Commands) == 0 {
+			return "\n  " + title + ": " + describeSequence(seq)
+		}
+		return "\n  " + title + ":" + strings.ReplaceAll(describeSequence(seq), "\n", "\n  ")
+	}
+	var b strings.Builder
+	b.WriteString(section("prefix", p.Prefix))
+	for i, br := range p.Branches {
+		b.WriteString(section(fmt.Sprintf("branch %d", i+1), br))
+	}
+	return b.String()
+}
+
+// runParallelProgram runs p against a fresh real system and reports results
+// that no linearization of the branches explains.
+func runParallelProgram[M, R, C any](t *testing.T, sm ModelStateMachine[M, R, C], p ParallelProgram[C]) {
+	sys := sm.Setup(t)
+	if sm.Teardown != nil {
+		defer sm.Teardown(sys)
+	}
+
+	model := sm.InitialModel
+	for i, val := range p.Prefix.Commands {
+		cmd := findModelCommand(sm, stepName(p.Prefix, i))
+		if cmd == nil {
+			continue
+		}
+		result := cmd.Run(sys, val)
+		after := cmd.Next(model, val)
+		if cmd.Postcondition != nil {
+			if err := cmd.Postcondition(model, val, after, result); err != nil {
+				t.Errorf("prefix step %d (%s %#v): %v", i+1, cmd.Name, val, err)
+				return
+			}
+		}
+		model = after
+	}
+
+	// run the branches concurrently, released together
+	results := make([][]any, len(p.Branches))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for b, br := range p.Branches {
+		results[b] = make([]any, len(br.Commands))
+		wg.Add(1)
+		go func(b int, br CommandSequence[C]) {
+			defer wg.Done()
+			<-start
+			for i, val := range br.Commands {
+				if cmd := findModelCommand(sm, stepName(br, i)); cmd != nil {
+					results[b][i] = cmd.Run(sys, val)
+				}
+			}
+		}(b, br)
+	}
+	close(start)
+	wg.Wait()
+
+	if !linearizable(sm, model, p.Branches, results, make([]int, len(p.Branches))) {
+		var b strings.Builder
+		for i, br := range p.Branches {
+			fmt.Fprintf(&b, "\n  branch %d:", i+1)
+			for j, val := range br.Commands {
+				fmt.Fprintf(&b, "\n    %d. %s: %#v -> %#v", j+1, stepName(br, j), val, results[i][j])
+			}
+		}
+		t.Errorf("no interleaving of the branches explains the observed results:%s", b.String())
+	}
+}
+
+// linearizable reports whether some interleaving of the remaining branch
+// commands (from pos on) explains their results, starting at model.
+func linearizable[M, R, C any](sm ModelStateMachine[M, R, C], model M, branches []CommandSequence[C], results [][]any, pos []int) bool {
+	done := true
+	for b, br := range branches {
+		i := pos[b]
+		if i >= len(br.Commands) {
+			continue
+		}
+		done = false
+		after := model
+		if cmd := findModelCommand(sm, stepName(br, i)); cmd != nil {
+			val := br.Commands[i]
+			if cmd.Precondition != nil && !cmd.Precondition(model, val) {
+				continue
+			}
+			after = cmd.Next(model, val)
+			if cmd.Postcondition != nil && cmd.Postcondition(model, val, after, results[b][i]) != nil {
+				continue
+			}
+		}
+		pos[b]++
+		ok := linearizable(sm, after, branches, results, pos)
+		pos[b]--
+		if ok {
+			return true
+		}
+	}
+	return done
+}
+
+// -------------------- generation and shrinking --------------------
+
+// parallelShape is the structure of a program: indices into its steps.
+type parallelShape struct {
+	prefix   []int
+	branches [][]int
+}
+
+// clone returns a deep copy of the shape.
+func (s parallelShape) clone() parallelShape {
+	out := parallelShape{prefix: append([]int(nil), s.prefix...), branches: make([][]int, len(s.branches))}
+	for i, br := range s.branches {
+		out.branches[i] = append([]int(nil), br...)
+	}
+	return out
+}
+
+// parallelProgramGenerator generates parallel programs on the model.
+type parallelProgramGenerator[S, C any] struct {
+	stateMachine StateMachine[S, C]
+
+	// prefix generates the commands of the sequential prefix
+	prefix commandSequenceGenerator[S, C]
+}
+
+// Generate implements the Generator interface for parallel programs.
+func (g parallelProgramGenerator[S, C]) Generate(r *rand.Rand, sz gen.Size) (ParallelProgram[C], gen.Shrinker[ParallelProgram[C]]) {
+	seqGen := g.prefix
+	seqGen.stateMachine = g.stateMachine
+	p := &parallelProgram[S, C]{sm: g.stateMachine}
+
+	// sequential prefix, advancing the model
+	state := g.stateMachine.InitialState
+	for n := seqGen.length(r, sz); len(p.shape.prefix) < n; {
+		cmd, val, shrink, ok := seqGen.nextCommand(r, sz, state)
+		if !ok {
+			break
+		}
+		p.shape.prefix = append(p.shape.prefix, p.add(cmd, val, shrink))
+		state = applyCommand(cmd, state, val)
+	}
+
+	// branches, each generated from the state after the prefix
+	p.shape.branches = make([][]int, parallelBranches)
+	for b := range p.shape.branches {
+		bstate := state
+		for n := 1 + r.Intn(parallelBranchLength); len(p.shape.branches[b]) < n; {
+			cmd, val, shrink, ok := seqGen.nextCommand(r, sz, bstate)
+			if !ok {
+				break
+			}
+			p.shape.branches[b] = append(p.shape.branches[b], p.add(cmd, val, shrink))
+			bstate = applyCommand(cmd, bstate, val)
+		}
+	}
+
+	// trim the longest branch until preconditions hold in every interleaving
+	for !p.valid(p.shape, -1, nil) {
+		longest := 0
+		for b, br := range p.shape.branches {
+			if len(br) > len(p.shape.branches[longest]) {
+				longest = b
+			}
+		}
+		br := p.shape.branches[longest]
+		p.shape.branches[longest] = br[:len(br)-1]
+	}
+
+	return p.build(p.shape, -1, nil), p.shrinker()
+}
+
+// applyCommand returns the model state after running cmd with val.
+func applyCommand[S, C any](cmd *Command[S, C], state S, val C) S {
+	if cmd.Execute != nil {
+		if next, err := cmd.Execute(state, val); err == nil {
+			return next
+		}
+	}
+	return state
+}
+
+// parallelProgram holds the steps of a generated program and shrinks it.
+type parallelProgram[S, C any] struct {
+	sm    StateMachine[S, C]
+	steps []sequenceStep[S, C]
+	vals  []C
+	shape parallelShape
+}
+
+// add records a generated step and returns its index.
+func (p *parallelProgram[S, C]) add(cmd *Command[S, C], val C, shrink gen.Shrinker[C]) int {
+	p.steps = append(p.steps, sequenceStep[S, C]{cmd: cmd, val: val, shrink: shrink})
+	p.vals = append(p.vals, val)
+	return len(p.steps) - 1
+}
+
+// value returns the value of step i, replaced by repl[0] if i is override.
+func (p *parallelProgram[S, C]) value(i, override int, repl []C) C {
+	if i == override {
+		return repl[0]
+	}
+	return p.vals[i]
+}
+
+// valid reports whether the preconditions of shape hold: sequentially for the
+// prefix, and in every interleaving for the branches.
+func (p *parallelProgram[S, C]) valid(shape parallelShape, override int, repl []C) bool {
+	state := p.sm.InitialState
+	for _, i := range shape.prefix {
+		cmd, val := p.steps[i].cmd, p.value(i, override, repl)
+		if cmd.Precondition != nil && !cmd.Precondition(state, val) {
+			return false
+		}
+		state = applyCommand(cmd, state, val)
+	}
+	return p.interleavingsValid(state, shape.branches, make([]int, len(shape.branches)), override, repl)
+}
+
+// interleavingsValid reports whether every interleaving of the branches from
+// pos on satisfies the preconditions, starting at state.
+func (p *parallelProgram[S, C]) interleavingsValid(state S, branches [][]int, pos []int, override int, repl []C) bool {
+	for b, br := range branches {
+		if pos[b] >= len(br) {
+			continue
+		}
+		i := br[pos[b]]
+		cmd, val := p.steps[i].cmd, p.value(i, override, repl)
+		if cmd.Precondition != nil && !cmd.Precondition(state, val) {
+			return false
+		}
+		pos[b]++
+		ok := p.interleavingsValid(applyCommand(cmd, state, val), branches, pos, override, repl)
+		pos[b]--
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// build assembles the program of shape.
+func (p *parallelProgram[S, C]) build(shape parallelShape, override int, repl []C) ParallelProgram[C] {
+	seq := func(idx []int) CommandSequence[C] {
+		s := CommandSequence[C]{Commands: make([]C, len(idx)), Names: make([]string, len(idx))}
+		for k, i := range idx {
+			s.Commands[k] = p.value(i, override, repl)
+			s.Names[k] = p.steps[i].cmd.Name
+		}
+		return s
+	}
+	out := ParallelProgram[C]{Prefix: seq(shape.prefix), Branches: make([]CommandSequence[C], len(shape.branches))}
+	for b, br := range shape.branches {
+		out.Branches[b] = seq(br)
+	}
+	return out
+}
+
+// shrinker returns the shrinker of the program. Like sequenceShrinker, it
+// alternates two phases until no progress is made:
+//
+//  1. structural candidates: remove a branch command, remove a prefix
+//     command, or move the first command of a branch to the end of the
+//     prefix (making the program more sequential);
+//  2. value shrinking, one step at a time.
+//
+// Candidates that break a precondition are never proposed.
+func (p *parallelProgram[S, C]) shrinker() gen.Shrinker[ParallelProgram[C]] {
+	var (
+		queue    []parallelShape
+		seen     map[string]struct{}
+		last     *parallelShape
+		order    []int // steps in value-shrinking order
+		pos      int
+		pending  C
+		proposed bool
+		removing bool
+		progress bool
+	)
+
+	push := func(s parallelShape) {
+		k := fmt.Sprint(s.prefix, s.branches)
+		if _, ok := seen[k]; ok {
+			return
+		}
+		seen[k] = struct{}{}
+		if p.valid(s, -1, nil) {
+			queue = append(queue, s)
+		}
+	}
+	grow := func() {
+		queue = queue[:0]
+		cur := p.shape
+		for b, br := range cur.branches {
+			for i := len(br) - 1; i >= 0; i-- {
+				c := cur.clone()
+				c.branches[b] = append(c.branches[b][:i], c.branches[b][i+1:]...)
+				push(c)
+			}
+		}
+		for i := len(cur.prefix) - 1; i >= 0; i-- {
+			c := cur.clone()
+			c.prefix = append(c.prefix[:i], c.prefix[i+1:]...)
+			push(c)
+		}
+		for b, br := range cur.branches {
+			if len(br) > 0 {
+				c := cur.clone()
+				c.prefix = append(c.prefix, br[0])
+				c.branches[b] = c.branches[b][1:]
+				push(c)
+			}
+		}
+	}
+	restart := func() {
+		removing, pos, progress, last = true, 0, false, nil
+		seen = map[string]struct{}{fmt.Sprint(p.shape.prefix, p.shape.branches): {}}
+		grow()
+		order = append([]int(nil), p.shape.prefix...)
+		for _, br := range p.shape.branches {
+			order = append(order, br...)
+		}
+	}
+	restart()
+
+	var next func(accept bool) (ParallelProgram[C], bool)
+	next = func(accept bool) (ParallelProgram[C], bool) {
+		if removing {
+			if accept && last != nil {
+				p.shape = *last
+				restart()
+			}
+			if len(queue) > 0 {
+				c := queue[0]
+				queue = queue[1:]
+				last = &c
+				return p.build(c, -1, nil), true
+			}
+			// the last structural candidate was rejected
+			removing, accept = false, false
+		}
+
+		if accept && proposed {
+			p.vals[order[pos]] = pending
+			progress = true
+		}
+		for pos < len(order) {
+			i := order[pos]
+			for shrink := p.steps[i].shrink; shrink != nil; {
+				v, ok := shrink(accept)
+				if !ok {
+					break
+				}
+				accept = false
+				if !p.valid(p.shape, i, []C{v}) {
+					continue
+				}
+				pending, proposed = v, true
+				return p.build(p.shape, i, []C{v}), true
+			}
+			pos++
+			accept, proposed = false, false
+		}
+		if progress {
+			restart()
+			return next(false)
+		}
+		return ParallelProgram[C]{}, false
+	}
+	return next
+}
@@ -0,0 +1,283 @@
+package prop
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// sharedCounter is the real system used by the parallel tests.
+type sharedCounter struct {
+	mu    sync.Mutex
+	n     atomic.Int64
+	buggy bool
+}
+
+// Add increments the counter by d and returns the new value. The buggy
+// counter reads and writes without holding the lock, losing updates.
+func (c *sharedCounter) Add(d int) int {
+	if c.buggy {
+		v := c.n.Load() + int64(d)
+		time.Sleep(100 * time.Microsecond)
+		c.n.Store(v)
+		return int(v)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int(c.n.Add(int64(d)))
+}
+
+// counterMachine returns a parallel model test of sharedCounter.
+func counterMachine(buggy bool) ModelStateMachine[int, *sharedCounter, int] {
+	return ModelStateMachine[int, *sharedCounter, int]{
+		Setup: func(t *testing.T) *sharedCounter { return &sharedCounter{buggy: buggy} },
+		Commands: []ModelCommand[int, *sharedCounter, int]{
+			{
+				Name:      "add",
+				Generator: gen.IntRange(1, 5),
+				Next:      func(m, d int) int { return m + d },
+				Run:       func(c *sharedCounter, d int) any { return c.Add(d) },
+				Postcondition: func(_ int, _ int, after int, result any) error {
+					if result.(int) != after {
+						return fmt.Errorf("add returned %v, model has %d", result, after)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// TestModelParallel_Linearizable tests that a correctly synchronized system
+// passes.
+func TestModelParallel_Linearizable(t *testing.T) {
+	TestModelParallel(t, counterMachine(false), Config{Seed: 1, Examples: 30, MaxShrink: 10})
+}
+
+// TestModelParallel_DetectsLostUpdates tests that results no interleaving
+// explains fail the program.
+func TestModelParallel_DetectsLostUpdates(t *testing.T) {
+	p := ParallelProgram[int]{
+		Branches: []CommandSequence[int]{
+			{Commands: []int{1}, Names: []string{"add"}},
+			{Commands: []int{1}, Names: []string{"add"}},
+		},
+	}
+
+	// the lost update depends on scheduling; retry a few times
+	failed := false
+	for i := 0; i < 20 && !failed; i++ {
+		failed = runCaptured(func(t *testing.T) { runParallelProgram(t, counterMachine(true), p) })
+	}
+	if !failed {
+		t.Error("Expected the lost update to be reported")
+	}
+
+	for i := 0; i < 20; i++ {
+		if runCaptured(func(t *testing.T) { runParallelProgram(t, counterMachine(false), p) }) {
+			t.Fatal("Expected the synchronized counter to be linearizable")
+		}
+	}
+}
+
+// TestModelParallel_RequiresNextAndRun tests the validation of model commands.
+func TestModelParallel_RequiresNextAndRun(t *testing.T) {
+	sm := counterMachine(false)
+	sm.Commands[0].Run = nil
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected TestModelParallel to panic on a command without Run")
+		}
+	}()
+	TestModelParallel(t, sm, Config{Examples: 1})
+}
+
+// TestLinearizable tests the search for an interleaving explaining results.
+func TestLinearizable(t *testing.T) {
+	sm := counterMachine(false)
+	branches := []CommandSequence[int]{
+		{Commands: []int{1, 2}, Names: []string{"add", "add"}},
+		{Commands: []int{3}, Names: []string{"add"}},
+	}
+	tests := []struct {
+		name    string
+		results [][]any
+		want    bool
+	}{
+		{"branch 1 first", [][]any{{1, 3}, {6}}, true},
+		{"interleaved", [][]any{{1, 6}, {4}}, true},
+		{"branch 2 first", [][]any{{4, 6}, {3}}, true},
+		{"lost update", [][]any{{1, 3}, {3}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linearizable(sm, 0, branches, tt.results, make([]int, len(branches))); got != tt.want {
+				t.Errorf("Expected linearizable = %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// boundedMachine allows an increment only below a limit, so the
+// preconditions of a branch depend on the other branches.
+func boundedMachine() StateMachine[int, int] {
+	return StateMachine[int, int]{
+		Commands: []Command[int, int]{
+			{
+				Name:         "inc",
+				Generator:    gen.IntRange(0, 3),
+				Precondition: func(s, d int) bool { return s+d <= 6 },
+				Execute:      func(s, d int) (int, error) { return s + d, nil },
+			},
+			{
+				Name:      "reset",
+				Generator: gen.Const(0),
+				Execute:   func(int, int) (int, error) { return 0, nil },
+			},
+		},
+	}
+}
+
+// programValid reports whether the preconditions of p hold in every
+// interleaving of its branches.
+func programValid(sm StateMachine[int, int], p ParallelProgram[int]) bool {
+	state := sm.InitialState
+	for i, v := range p.Prefix.Commands {
+		cmd := findMatchingCommand(sm, stepName(p.Prefix, i))
+		if cmd.Precondition != nil && !cmd.Precondition(state, v) {
+			return false
+		}
+		state = applyCommand(cmd, state, v)
+	}
+	var walk func(s int, pos []int) bool
+	walk = func(s int, pos []int) bool {
+		for b, br := range p.Branches {
+			if pos[b] >= len(br.Commands) {
+				continue
+			}
+			cmd, v := findMatchingCommand(sm, stepName(br, pos[b])), br.Commands[pos[b]]
+			if cmd.Precondition != nil && !cmd.Precondition(s, v) {
+				return false
+			}
+			pos[b]++
+			ok := walk(applyCommand(cmd, s, v), pos)
+			pos[b]--
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return walk(state, make([]int, len(p.Branches)))
+}
+
+// TestParallelProgramGenerator_PreconditionsHold tests that generated programs
+// satisfy the preconditions in every interleaving of their branches.
+func TestParallelProgramGenerator_PreconditionsHold(t *testing.T) {
+	sm := boundedMachine()
+	g := parallelProgramGenerator[int, int]{stateMachine: sm}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		p, _ := g.Generate(r, gen.Size{})
+		if len(p.Branches) != parallelBranches {
+			t.Fatalf("Expected %[2]d branches, got %[1]d", len(p.Branches), parallelBranches)
+		}
+		for _, br := range p.Branches {
+			if len(br.Commands) > parallelBranchLength {
+				t.Fatalf("Expected branches of at most %[2]d commands, got %[1]d", len(br.Commands), parallelBranchLength)
+			}
+		}
+		if !programValid(sm, p) {
+			t.Fatalf("Expected preconditions to hold, got program:%s", describeProgram(p))
+		}
+	}
+}
+
+// TestParallelProgramGenerator_PrefixSteps tests that the length of the
+// prefix follows the step bounds of the configuration.
+func TestParallelProgramGenerator_PrefixSteps(t *testing.T) {
+	model := counterMachine(false).modelMachine()
+	seqGen, _ := sequenceRun(model, Config{MinSteps: 3, MaxSteps: 3})
+	g := parallelProgramGenerator[int, int]{stateMachine: model, prefix: seqGen}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		p, _ := g.Generate(r, gen.Size{Scale: 1})
+		if len(p.Prefix.Commands) != 3 {
+			t.Fatalf("Expected a prefix of 3 commands, got %d", len(p.Prefix.Commands))
+		}
+	}
+}
+
+// TestParallelProgramShrinker tests that failing programs shrink to a single
+// branch command, proposing only valid programs.
+func TestParallelProgramShrinker(t *testing.T) {
+	sm := boundedMachine()
+	g := parallelProgramGenerator[int, int]{stateMachine: sm}
+
+	// the property fails whenever a branch contains an inc
+	fails := func(p ParallelProgram[int]) bool {
+		for _, br := range p.Branches {
+			for _, n := range br.Names {
+				if n == "inc" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 20; i++ {
+		p, shrink := g.Generate(r, gen.Size{})
+		if !fails(p) {
+			continue
+		}
+		accept := true
+		for steps := 0; steps < 10000; steps++ {
+			cand, ok := shrink(accept)
+			if !ok {
+				break
+			}
+			if !programValid(sm, cand) {
+				t.Fatalf("Expected only valid candidates, got program:%s", describeProgram(cand))
+			}
+			accept = fails(cand)
+			if accept {
+				p = cand
+			}
+		}
+
+		var left []int
+		for _, br := range p.Branches {
+			left = append(left, br.Commands...)
+		}
+		if len(p.Prefix.Commands) != 0 || len(left) != 1 || left[0] != 0 {
+			t.Fatalf("Expected a single inc 0 in a branch, got program:%s", describeProgram(p))
+		}
+		return
+	}
+	t.Fatal("Expected a failing program to be generated")
+}
+
+// TestDescribeProgram tests the formatting of parallel programs.
+func TestDescribeProgram(t *testing.T) {
+	p := ParallelProgram[int]{
+		Prefix: CommandSequence[int]{Commands: []int{1}, Names: []string{"inc"}},
+		Branches: []CommandSequence[int]{
+			{Commands: []int{2}, Names: []string{"inc"}},
+			{},
+		},
+	}
+	got := describeProgram(p)
+	for _, want := range []string{"prefix:\n    1. inc: 1", "branch 1:\n    1. inc: 2", "branch 2: (no commands)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in %q", want, got)
+		}
+	}
+}
@@ -4,10 +4,15 @@
 package prop
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"iter"
 	"math/rand"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,12 +37,87 @@ type Config struct {
 	ShrinkStrat string
 
 	// StopOnFirstFailure determines whether to stop testing
-	// after the first failing test case is found.
+	// after the first failing test case is found. When false, the run keeps
+	// exploring and shrinks every distinct failure: failures with the same
+	// minimal counterexample or panic site are reported once, listing the
+	// other examples that hit them.
 	StopOnFirstFailure bool
 
 	// Parallelism specifies the number of parallel workers to use
 	// for running test cases. Must be at least 1.
 	Parallelism int
+
+	// FailureDir is the directory where minimized counterexamples are stored,
+	// keyed by test name. Stored counterexamples are replayed before any new
+	// example is generated. If empty, counterexamples are not persisted.
+	FailureDir string
+
+	// MinSize and MaxSize bound the size hint (gen.Size.Scale) passed to the
+	// generator. The size grows linearly from MinSize on the first example to
+	// MaxSize on the last, so early examples are tiny and later ones stress
+	// large inputs. Zero values default to 1 and 100.
+	MinSize int
+	MaxSize int
+
+	// Example, when positive, regenerates and runs only the example with that
+	// 1-based index (the K of "ex#K"). Each example derives its own seed from
+	// Seed, so ex#K receives the same input regardless of Parallelism.
+	Example int
+
+	// MinSteps and MaxSteps bound the number of commands of the sequences run
+	// by TestStateMachine and TestModel, and of the sequential prefix of the
+	// programs run by TestModelParallel (whose concurrent branches stay short).
+	// Like the size hint, the longest sequence allowed grows from MinSteps on
+	// the first example to MaxSteps on the last (unless FixedSteps is set), and
+	// each sequence draws its length up to that bound. Zero values default to 0 and 20.
+	MinSteps int
+	MaxSteps int
+
+	// FixedSteps disables the growth of the sequence length with the size
+	// hint: every sequence, from the first, draws its length between MinSteps
+	// and MaxSteps.
+	FixedSteps bool
+
+	// Sequences, when positive, is the number of command sequences run by the
+	// state machine tests instead of Examples.
+	Sequences int
+
+	// StateMachineExpr is the Go expression that builds the state machine
+	// under test, e.g. "newBankMachine()". TestStateMachine writes it in the
+	// regression test printed with a failure, which then runs as pasted;
+	// when empty, the regression test declares a variable to replace.
+	StateMachineExpr string
+
+	// ShrinkSubtests runs every shrink candidate as the subtest
+	// ex#K/shrink#N. By default candidates run on an internal *testing.T
+	// that registers no subtest and prints nothing, and only the original
+	// failure and the minimal one, run again as ex#K/min, are reported. The
+	// internal T does not support t.Run, t.Parallel, t.Deadline or t.Cleanup
+	// (nor t.TempDir, t.Setenv and t.Chdir, which rely on it), and its
+	// t.Context is nil. Shrinking goes back to subtests for the remaining
+	// candidates as soon as one of them uses the first four; the functions
+	// that candidate registered with t.Cleanup never run. Set it for bodies
+	// that need them from the first candidate.
+	ShrinkSubtests bool
+
+	// Timeout, when positive, bounds the time each example may run. An
+	// example that has not returned by then fails, and is shrunk like any
+	// other failure toward the smallest input that still hangs. The goroutine
+	// running a hung example cannot be stopped and is abandoned.
+	Timeout time.Duration
+
+	// Duration, when positive, turns the run into a time budget: examples
+	// are generated until it elapses, instead of Examples of them. The run
+	// also stops early enough to shrink and report a failure before the
+	// deadline of the test (go test -timeout). The size hint still ramps up
+	// over the first Examples examples and stays at MaxSize afterwards, so
+	// every ex#K can be replayed with Example.
+	Duration time.Duration
+
+	// ShrinkDuration, when positive, bounds the time spent shrinking a
+	// failure, alongside MaxShrink: shrinking stops at whichever limit is
+	// reached first. Shrinking always stops before the deadline of the test.
+	ShrinkDuration time.Duration
 }
 
 var (
@@ -60,6 +140,54 @@ var (
 	// flagParallelism sets the number of parallel workers.
 	// Default: 1.
 	flagParallelism = flag.Int("rapidx.shrink.parallel", 1, "Number of parallel workers")
+
+	// flagFailureDir sets the directory of the failure database.
+	// Default: "testdata/rapidx". An empty value disables persistence.
+	flagFailureDir = flag.String("rapidx.failuredir", "testdata/rapidx", "Directory for stored counterexamples (empty disables)")
+
+	// flagMinSize sets the size hint of the first example.
+	// Default: 1.
+	flagMinSize = flag.Int("rapidx.minsize", defaultMinSize, "Size hint of the first example")
+
+	// flagMaxSize sets the size hint of the last example.
+	// Default: 100.
+	flagMaxSize = flag.Int("rapidx.maxsize", defaultMaxSize, "Size hint of the last example")
+
+	// flagExample selects a single example (ex#K) to regenerate and run.
+	// Default: 0 (run all examples).
+	flagExample = flag.Int("rapidx.example", 0, "Run only the example with this 1-based index (0 = all)")
+
+	// flagMinSteps sets the minimum length of state machine sequences.
+	// Default: 0.
+	flagMinSteps = flag.Int("rapidx.steps.min", 0, "Minimum number of commands of a state machine sequence")
+
+	// flagMaxSteps sets the maximum length of state machine sequences.
+	// Default: 20.
+	flagMaxSteps = flag.Int("rapidx.steps.max", defaultMaxSteps, "Maximum number of commands of a state machine sequence")
+
+	// flagFixedSteps lets every state machine sequence reach the maximum length.
+	// Default: false (the maximum length grows with the size hint).
+	flagFixedSteps = flag.Bool("rapidx.steps.fixed", false, "Do not scale the length of state machine sequences with the size hint")
+
+	// flagSequences sets the number of state machine sequences to run.
+	// Default: 0 (use the number of examples).
+	flagSequences = flag.Int("rapidx.sequences", 0, "Number of state machine sequences (0 = rapidx.examples)")
+
+	// flagShrinkSubtests runs every shrink candidate as a subtest.
+	// Default: false.
+	flagShrinkSubtests = flag.Bool("rapidx.shrink.subtests", false, "Run every shrink candidate as a subtest")
+
+	// flagTimeout bounds the time each example may run.
+	// Default: 0 (no timeout).
+	flagTimeout = flag.Duration("rapidx.timeout", 0, "Time limit of each example (0 = none)")
+
+	// flagDuration sets the time budget of the run.
+	// Default: 0 (run rapidx.examples examples).
+	flagDuration = flag.Duration("rapidx.duration", 0, "Generate examples for this long (0 = rapidx.examples)")
+
+	// flagShrinkDuration sets the time budget of shrinking.
+	// Default: 0 (only rapidx.maxshrink applies).
+	flagShrinkDuration = flag.Duration("rapidx.shrink.duration", 0, "Time limit of shrinking a failure (0 = none)")
 )
 
 // Default returns a Config with default values based on command-line flags.
@@ -72,6 +200,18 @@ func Default() Config {
 		ShrinkStrat:        *flagShrinkStrat,
 		StopOnFirstFailure: true,
 		Parallelism:        *flagParallelism,
+		FailureDir:         *flagFailureDir,
+		MinSize:            *flagMinSize,
+		MaxSize:            *flagMaxSize,
+		Example:            *flagExample,
+		MinSteps:           *flagMinSteps,
+		MaxSteps:           *flagMaxSteps,
+		FixedSteps:         *flagFixedSteps,
+		Sequences:          *flagSequences,
+		ShrinkSubtests:     *flagShrinkSubtests,
+		Timeout:            *flagTimeout,
+		Duration:           *flagDuration,
+		ShrinkDuration:     *flagShrinkDuration,
 	}
 }
 
@@ -84,6 +224,129 @@ func (c Config) effectiveSeed() int64 {
 	return time.Now().UnixNano()
 }
 
+// Default bounds of the size hint when Config.MinSize/MaxSize are not set.
+const (
+	defaultMinSize = 1
+	defaultMaxSize = 100
+)
+
+// Default maximum length of state machine sequences when Config.MaxSteps is
+// not set.
+const defaultMaxSteps = 20
+
+// sizeBounds returns the size hints of the first and last examples.
+func (c Config) sizeBounds() (lo, hi int) {
+	lo, hi = c.MinSize, c.MaxSize
+	if lo <= 0 {
+		lo = defaultMinSize
+	}
+	if hi <= 0 {
+		hi = defaultMaxSize
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// sizeFor returns the size passed to the generator for the example at index i.
+// The scale ramps linearly from MinSize (first example) to MaxSize (last example),
+// and the shrinking strategy is the one of the run.
+func (c Config) sizeFor(i int) gen.Size {
+	lo, hi := c.sizeBounds()
+	if c.Examples <= 1 || i >= c.Examples-1 {
+		return gen.Size{Scale: hi, Strategy: c.strategy()}
+	}
+	return gen.Size{Scale: lo + (hi-lo)*i/(c.Examples-1), Strategy: c.strategy()}
+}
+
+// sizeFlags returns the command-line flags that reproduce the sizes of the
+// examples of a run: the size of ex#K depends on the number of examples and
+// the size bounds, and the length of state machine sequences on the step
+// bounds and FixedSteps (only listed when they are not the defaults).
+func (c Config) sizeFlags() string {
+	lo, hi := c.sizeBounds()
+	flags := fmt.Sprintf("-rapidx.examples=%d -rapidx.minsize=%d -rapidx.maxsize=%d", c.Examples, lo, hi)
+	maxSteps := c.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxSteps
+	}
+	if c.MinSteps > 0 || maxSteps != defaultMaxSteps {
+		flags += fmt.Sprintf(" -rapidx.steps.min=%d -rapidx.steps.max=%d", max(c.MinSteps, 0), maxSteps)
+	}
+	if c.FixedSteps {
+		flags += " -rapidx.steps.fixed"
+	}
+	return flags
+}
+
+// strategy returns the shrinking strategy of the run: "dfs" if ShrinkStrat
+// says so, "bfs" otherwise. It is passed to the generators in gen.Size, so
+// tests running in parallel with different strategies do not interfere.
+func (c Config) strategy() string {
+	if c.ShrinkStrat == gen.ShrinkStrategyDFS {
+		return gen.ShrinkStrategyDFS
+	}
+	return gen.ShrinkStrategyBFS
+}
+
+// exampleIndices returns the 0-based indices of the examples to run: only
+// the one selected by Example, the first Examples, or, when Duration is set,
+// as many as fit in the time budget of t (at least one).
+func (c Config) exampleIndices(t *testing.T) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		switch {
+		case c.Example > 0:
+			yield(c.Example - 1)
+		case c.Duration > 0:
+			end := budgetEnd(t, c.Duration)
+			for i := 0; i == 0 || time.Now().Before(end); i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		default:
+			for i := 0; i < c.Examples; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// budgetEnd returns when a phase with the time budget d must end: d from now
+// (no limit if d is zero), and in any case before the deadline of t, keeping
+// a tenth of the time left to shrink and report failures. The zero Time
+// means no limit.
+func budgetEnd(t *testing.T, d time.Duration) time.Time {
+	var end time.Time
+	if d > 0 {
+		end = time.Now().Add(d)
+	}
+	if deadline, ok := t.Deadline(); ok {
+		deadline = deadline.Add(-time.Until(deadline) / 10)
+		if end.IsZero() || deadline.Before(end) {
+			end = deadline
+		}
+	}
+	return end
+}
+
+// exampleSeed derives the seed of the example at index i from the master seed
+// (splitmix64 finalizer), so any example can be regenerated on its own.
+func exampleSeed(seed int64, i int) int64 {
+	z := uint64(seed) + uint64(i+1)*0x9e3779b97f4a7c15 // #nosec G115 -- Bit mixing, overflow intended
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31)) // #nosec G115 -- Bit mixing, overflow intended
+}
+
+// exampleRand returns the random source of the example at index i.
+func exampleRand(seed int64, i int) *rand.Rand {
+	return rand.New(rand.NewSource(exampleSeed(seed, i))) // #nosec G404 -- Using math/rand for deterministic property-based testing
+}
+
 // ForAll creates a property-based test that generates test cases using the provided generator
 // and runs them against the given test function. It returns a function that takes the test
 // body as a parameter.
@@ -91,6 +354,9 @@ func (c Config) effectiveSeed() int64 {
 // The test will generate cfg.Examples number of test cases, and if any fail, it will attempt
 // to shrink the counterexample to find a minimal failing case.
 //
+// When cfg.FailureDir is set, the minimal counterexample is stored there and
+// replayed first on every later run, before new examples are generated.
+//
 // Example usage:
 //
 //	ForAll(t, prop.Default(), gen.Int())(func(t *testing.T, x int) {
@@ -101,87 +367,147 @@ func (c Config) effectiveSeed() int64 {
 //	})
 func ForAll[T any](t *testing.T, cfg Config, g gen.Generator[T]) func(func(*testing.T, T)) {
 	return func(body func(*testing.T, T)) {
-		seed := cfg.effectiveSeed()
-		r := rand.New(rand.NewSource(seed)) // #nosec G404 -- Using math/rand for deterministic property-based testing
-		gen.SetShrinkStrategy(cfg.ShrinkStrat)
+		forAll(t, cfg, g, body, describeValue[T])
+	}
+}
 
-		t.Logf("[rapidx] seed=%d examples=%d maxshrink=%d strategy=%s parallelism=%d",
-			seed, cfg.Examples, cfg.MaxShrink, cfg.ShrinkStrat, cfg.Parallelism)
+// ForAll2 is ForAll for properties of two independent arguments.
+// Each argument is shrunk in turn while the other is held fixed, and the
+// failure report lists the minimal arguments separately.
+//
+// Example usage:
+//
+//	ForAll2(t, prop.Default(), gen.Int(), gen.Int())(func(t *testing.T, a, b int) {
+//	    if a+b != b+a {
+//	        t.Errorf("addition is not commutative for %d, %d", a, b)
+//	    }
+//	})
+func ForAll2[A, B any](t *testing.T, cfg Config, ga gen.Generator[A], gb gen.Generator[B]) func(func(*testing.T, A, B)) {
+	return func(body func(*testing.T, A, B)) {
+		forAll(t, cfg, gen.Tuple2(ga, gb),
+			func(t *testing.T, p gen.Pair[A, B]) { body(t, p.First, p.Second) },
+			func(p gen.Pair[A, B]) string { return describeArgs(p.First, p.Second) })
+	}
+}
 
-		if cfg.Parallelism <= 1 {
-			runSequential(t, cfg, g, body, seed, r)
-		} else {
-			runParallel(t, cfg, g, body, seed, r)
-		}
+// ForAll3 is ForAll for properties of three independent arguments.
+// Each argument is shrunk in turn while the others are held fixed, and the
+// failure report lists the minimal arguments separately.
+func ForAll3[A, B, C any](t *testing.T, cfg Config, ga gen.Generator[A], gb gen.Generator[B], gc gen.Generator[C]) func(func(*testing.T, A, B, C)) {
+	return func(body func(*testing.T, A, B, C)) {
+		forAll(t, cfg, gen.Tuple3(ga, gb, gc),
+			func(t *testing.T, p gen.Triple[A, B, C]) { body(t, p.First, p.Second, p.Third) },
+			func(p gen.Triple[A, B, C]) string { return describeArgs(p.First, p.Second, p.Third) })
+	}
+}
+
+// forAll runs the property; describe formats a counterexample for reports.
+func forAll[T any](t *testing.T, cfg Config, g gen.Generator[T], body func(*testing.T, T), describe func(T) string) {
+	seed := cfg.effectiveSeed()
+
+	t.Logf("[rapidx] seed=%d examples=%d maxshrink=%d strategy=%s parallelism=%d",
+		seed, cfg.Examples, cfg.MaxShrink, cfg.ShrinkStrat, cfg.Parallelism)
+	if cfg.Duration > 0 && cfg.Example <= 0 {
+		t.Logf("[rapidx] time budget: generating examples for %v", cfg.Duration)
+	}
+
+	replayFailures(t, cfg, body, describe)
+
+	cov := newCoverage()
+	if cfg.Parallelism <= 1 {
+		runSequential(t, cfg, g, body, cov, describe, seed)
+	} else {
+		runParallel(t, cfg, g, body, cov, describe, seed)
+	}
+
+	// coverage requirements are meaningless when replaying a single example
+	cov.report(t, cfg.Example <= 0)
+}
+
+// describeValue formats a single-argument counterexample.
+func describeValue[T any](v T) string {
+	return fmt.Sprintf("%#v", v)
+}
+
+// describeArgs formats the arguments of a multi-argument counterexample,
+// one per line.
+func describeArgs(args ...any) string {
+	var b strings.Builder
+	for i, a := range args {
+		fmt.Fprintf(&b, "\n  arg %d: %#v", i+1, a)
 	}
+	return b.String()
 }
 
 // runSequential executes property-based tests sequentially (single-threaded).
 // It generates test cases one by one and runs them against the test function.
-// If a test fails, it attempts to shrink the counterexample.
-func runSequential[T any](t *testing.T, cfg Config, g gen.Generator[T], body func(*testing.T, T), seed int64, r *rand.Rand) {
-	for i := 0; i < cfg.Examples; i++ {
-		val, shrink := g.Generate(r, gen.Size{})
+// If a test fails, it attempts to shrink the counterexample. Only the runs of
+// the examples count toward the label distribution in cov, not the runs of
+// shrink candidates.
+func runSequential[T any](t *testing.T, cfg Config, g gen.Generator[T], body func(*testing.T, T), cov *coverage, describe func(T) string, seed int64) {
+	tracked := track(cov, body)
+	failures := newFailureSet()
+	run := 0
+	for i := range cfg.exampleIndices(t) {
+		run++
+		val, shrink := g.Generate(exampleRand(seed, i), cfg.sizeFor(i))
 		name := fmt.Sprintf("ex#%d", i+1)
 
-		passed := t.Run(name, func(st *testing.T) { body(st, val) })
-		if passed {
+		var reason string
+		passed := t.Run(name, func(st *testing.T) { reason = runExample(st, cfg.Timeout, tracked, val) })
+		if passed || failures.duplicate(i, describe(val), reason) {
 			continue
 		}
 
-		min := val
-		steps := 0
-		acceptedPrev := true
-
-		for steps < cfg.MaxShrink {
-			next, ok := shrink(acceptedPrev)
-			if !ok {
-				break
-			}
-			steps++
-			sname := fmt.Sprintf("%s/shrink#%d", name, steps)
-
-			stillFails := !t.Run(sname, func(st *testing.T) { body(st, next) })
-			if stillFails {
-				min = next
-				acceptedPrev = true
-			} else {
-				acceptedPrev = false
-			}
-		}
-
-		full := fmt.Sprintf("^%s$/%s(/|$)", t.Name(), name)
-		t.Fatalf("[rapidx] property failed; seed=%d; examples_run=%d; shrunk_steps=%d\n"+
-			"counterexample (min): %#v\nreplay: go test -run '%s' -rapidx.seed=%d",
-			seed, i+1, steps, min, full, seed)
-
+		failures.add(shrinkExample(t, cfg, i, name, val, reason, shrink, body, describe))
 		if cfg.StopOnFirstFailure {
-			return
+			break
 		}
 	}
+	logBudget(t, cfg, run)
+	failures.report(t, cfg, seed)
+}
+
+// logBudget logs how many examples fit in the time budget of the run, if
+// it has one.
+func logBudget(t *testing.T, cfg Config, run int) {
+	if cfg.Duration > 0 && cfg.Example <= 0 {
+		t.Logf("[rapidx] time budget of %v spent: %d examples run", cfg.Duration, run)
+	}
 }
 
 // runParallel executes property-based tests in parallel using multiple goroutines.
 // It distributes test cases across multiple workers and collects failure results.
-// The random number generator is protected by a mutex to ensure thread safety.
-func runParallel[T any](t *testing.T, cfg Config, g gen.Generator[T], body func(*testing.T, T), seed int64, r *rand.Rand) {
+// Every example is generated from its own seed, so the value of ex#K does not
+// depend on which worker picks it up. As in runSequential, only the runs of the
+// examples are tracked in cov.
+func runParallel[T any](t *testing.T, cfg Config, g gen.Generator[T], body func(*testing.T, T), cov *coverage, describe func(T) string, seed int64) {
+	tracked := track(cov, body)
+
 	// Create a channel to distribute test indices to workers
-	testChan := make(chan int, cfg.Examples)
+	testChan := make(chan int, cfg.Parallelism)
 
-	// Send all test indices to the channel
-	for i := 0; i < cfg.Examples; i++ {
-		testChan <- i
-	}
-	close(testChan)
+	// Send test indices to the channel until the run is over or stopped
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	var run atomic.Int64
+	go func() {
+		defer close(testChan)
+		for i := range cfg.exampleIndices(t) {
+			select {
+			case testChan <- i:
+				run.Add(1)
+			case <-stop:
+				return
+			}
+		}
+	}()
 
 	// WaitGroup to coordinate worker goroutines
 	var wg sync.WaitGroup
 
-	// Mutex to protect the shared random number generator
-	var randMutex sync.Mutex
-
-	// Channel to collect failure results from workers
-	failureChan := make(chan failureResult, cfg.Examples)
+	// Distinct failures found by the workers
+	failures := newFailureSet()
 
 	// Start worker goroutines
 	for i := 0; i < cfg.Parallelism; i++ {
@@ -191,73 +517,115 @@ func runParallel[T any](t *testing.T, cfg Config, g gen.Generator[T], body func(
 
 			// Process test cases from the channel
 			for testIndex := range testChan {
-				// Generate test case (protected by mutex for thread safety)
-				randMutex.Lock()
-				val, shrink := g.Generate(r, gen.Size{})
-				randMutex.Unlock()
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				// Generate test case from the example's own seed
+				val, shrink := g.Generate(exampleRand(seed, testIndex), cfg.sizeFor(testIndex))
 
 				name := fmt.Sprintf("ex#%d", testIndex+1)
 
 				// Run the test case
-				passed := t.Run(name, func(st *testing.T) { body(st, val) })
-				if passed {
+				var reason string
+				passed := t.Run(name, func(st *testing.T) { reason = runExample(st, cfg.Timeout, tracked, val) })
+				if passed || failures.duplicate(testIndex, describe(val), reason) {
 					continue
 				}
 
-				// Test failed, attempt to shrink the counterexample
-				min := val
-				steps := 0
-				acceptedPrev := true
-
-				for steps < cfg.MaxShrink {
-					next, ok := shrink(acceptedPrev)
-					if !ok {
-						break
-					}
-					steps++
-					sname := fmt.Sprintf("%s/shrink#%d", name, steps)
-
-					stillFails := !t.Run(sname, func(st *testing.T) { body(st, next) })
-					if stillFails {
-						min = next
-						acceptedPrev = true
-					} else {
-						acceptedPrev = false
-					}
-				}
-
-				// Send failure result to the channel
-				failureChan <- failureResult{
-					testIndex: testIndex,
-					name:      name,
-					min:       min,
-					steps:     steps,
-				}
+				// Test failed, shrink the counterexample and record it
+				failures.add(shrinkExample(t, cfg, testIndex, name, val, reason, shrink, body, describe))
 
 				if cfg.StopOnFirstFailure {
+					stopOnce.Do(func() { close(stop) })
 					return
 				}
 			}
 		}(i)
 	}
 
-	// Close the failure channel when all workers are done
-	go func() {
-		wg.Wait()
-		close(failureChan)
-	}()
+	// Report the failures once all workers are done
+	wg.Wait()
+	logBudget(t, cfg, int(run.Load()))
+	failures.report(t, cfg, seed)
+}
 
-	// Process failure results and report them
-	for failure := range failureChan {
-		full := fmt.Sprintf("^%s$/%s(/|$)", t.Name(), failure.name)
-		t.Fatalf("[rapidx] property failed; seed=%d; examples_run=%d; shrunk_steps=%d\n"+
-			"counterexample (min): %#v\nreplay: go test -run '%s' -rapidx.seed=%d",
-			seed, failure.testIndex+1, failure.steps, failure.min, full, seed)
+// shrinkExample shrinks the failing example at index i, run as the subtest
+// name, and returns its failure. reason is how the example failed, as
+// returned by runExample, which identifies the failure along with the
+// minimal counterexample.
+func shrinkExample[T any](t *testing.T, cfg Config, i int, name string, val T, reason string, shrink gen.Shrinker[T], body func(*testing.T, T), describe func(T) string) failureResult {
+	min, steps := shrinkFailure(t, cfg, name, val, reason, shrink, body)
+	return failureResult{testIndex: i, name: name, min: min, desc: describe(min), steps: steps, reason: reason}
+}
 
-		if cfg.StopOnFirstFailure {
-			return
+// shrinkFailure shrinks the failing value val of the subtest name. Candidates
+// run as the subtests name/shrink#N if cfg.ShrinkSubtests is set, or quietly
+// (see failsQuietly); in the latter case the smallest failing value is run
+// once more as the subtest name/min, so its failure messages are reported,
+// and a candidate that the internal T cannot run sends the remaining ones
+// back to subtests. Unless cfg.StopOnFirstFailure is set, a candidate only
+// counts as failing if it fails the same way as val, which failed for
+// reason: both panic, both time out, or neither does. It returns that value
+// and the number of candidates tried: at most cfg.MaxShrink, and no more
+// than fit in cfg.ShrinkDuration and before the deadline of t.
+func shrinkFailure[T any](t *testing.T, cfg Config, name string, val T, reason string, shrink gen.Shrinker[T], body func(*testing.T, T)) (T, int) {
+	min := val
+	steps := 0
+	quiet := !cfg.ShrinkSubtests
+	minQuiet := false // min was found quietly and has not run as a subtest
+	acceptedPrev := true
+	end := budgetEnd(t, cfg.ShrinkDuration)
+
+	// fallBack sends the remaining candidates to subtests if the internal T
+	// could not run the body
+	fallBack := func(reason string) {
+		if unsupported(reason) {
+			t.Logf("[rapidx] shrinking with subtests: %s", reason)
+			quiet = false
 		}
 	}
+
+	for steps < cfg.MaxShrink {
+		if !end.IsZero() && time.Now().After(end) {
+			t.Logf("[rapidx] shrinking stopped by the time budget after %d steps", steps)
+			break
+		}
+		next, ok := shrink(acceptedPrev)
+		if !ok {
+			break
+		}
+		steps++
+
+		var stillFails bool
+		var nextReason string
+		if quiet {
+			stillFails, nextReason = failsQuietly(body, next, cfg.Timeout)
+			fallBack(nextReason)
+		}
+		if !quiet {
+			sname := fmt.Sprintf("%s/shrink#%d", name, steps)
+			stillFails = !t.Run(sname, func(st *testing.T) { nextReason = runExample(st, cfg.Timeout, body, next) })
+		}
+		// when collecting every failure, keep a panic or a timeout from
+		// shrinking into a different failure, which would hide it
+		stillFails = stillFails && (cfg.StopOnFirstFailure || sameKind(nextReason, reason))
+		if stillFails {
+			min, minQuiet = next, quiet
+			acceptedPrev = true
+		} else {
+			acceptedPrev = false
+		}
+	}
+
+	if minQuiet {
+		if t.Run(name+"/min", func(st *testing.T) { runExample(st, cfg.Timeout, body, min) }) {
+			t.Logf("[rapidx] the minimal counterexample passed when rerun; the property may be flaky")
+		}
+	}
+	return min, steps
 }
 
 // failureResult holds information about a failed test case after shrinking.
@@ -271,8 +639,117 @@ type failureResult struct {
 	// min is the minimal counterexample found through shrinking.
 	min interface{}
 
+	// desc is the formatted counterexample used in the failure report.
+	desc string
+
 	// steps is the number of shrinking steps performed.
 	steps int
+
+	// reason is how the example failed (see runExample).
+	reason string
+
+	// also holds the indices of other failing examples found to be the
+	// same failure.
+	also []int
+}
+
+// keys returns the keys identifying the failure: its minimal counterexample
+// and, for panics, the site of the panic. Timeouts are only identified by
+// their counterexample, since hangs of unrelated causes all time out the
+// same way.
+func (f *failureResult) keys() []string {
+	keys := []string{"min:" + f.desc}
+	if f.reason != "" && !strings.HasPrefix(f.reason, timeoutReason) {
+		keys = append(keys, "reason:"+f.reason)
+	}
+	return keys
+}
+
+// occurrences notes the other examples that failed the same way, if any.
+func (f *failureResult) occurrences() string {
+	if len(f.also) == 0 {
+		return ""
+	}
+	sort.Ints(f.also)
+	names := make([]string, len(f.also))
+	for i, idx := range f.also {
+		names[i] = fmt.Sprintf("ex#%d", idx+1)
+	}
+	return "\nsame failure in: " + strings.Join(names, ", ")
+}
+
+// failureSet collects the distinct failures of a run. Failures sharing a key
+// (see failureResult.keys) are taken for the same bug and reported once. It
+// is safe for concurrent use.
+type failureSet struct {
+	mu       sync.Mutex
+	failures []*failureResult
+	byKey    map[string]*failureResult
+}
+
+// newFailureSet returns an empty failureSet.
+func newFailureSet() *failureSet {
+	return &failureSet{byKey: map[string]*failureResult{}}
+}
+
+// duplicate reports whether the example at index i, with value desc, failed
+// like a known failure (see failureResult.keys), recording it as another
+// occurrence of that failure. Such an example is not shrunk.
+func (s *failureSet) duplicate(i int, desc, reason string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.known(&failureResult{testIndex: i, desc: desc, reason: reason})
+}
+
+// known reports whether f is a known failure, recording its example as
+// another occurrence of it. It must be called with s.mu held.
+func (s *failureSet) known(f *failureResult) bool {
+	for _, k := range f.keys() {
+		if known, ok := s.byKey[k]; ok {
+			known.also = append(known.also, f.testIndex)
+			return true
+		}
+	}
+	return false
+}
+
+// add records f, or only its example if f is a known failure.
+func (s *failureSet) add(f failureResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.known(&f) {
+		return
+	}
+	s.failures = append(s.failures, &f)
+	for _, k := range f.keys() {
+		s.byKey[k] = &f
+	}
+}
+
+// report fails t with every distinct failure of the run, in example order,
+// storing their counterexamples. If the run stops at the first failure, it
+// also ends the test.
+func (s *failureSet) report(t *testing.T, cfg Config, seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.failures) == 0 {
+		return
+	}
+	sort.Slice(s.failures, func(i, j int) bool { return s.failures[i].testIndex < s.failures[j].testIndex })
+
+	if len(s.failures) > 1 {
+		t.Errorf("[rapidx] %d distinct failures; seed=%d", len(s.failures), seed)
+	}
+	for _, f := range s.failures {
+		full := fmt.Sprintf("^%s$/%s(/|$)", t.Name(), f.name)
+		stored := persistFailure(t, cfg, f.min)
+		t.Errorf("[rapidx] property failed; seed=%d; examples_run=%d; shrunk_steps=%d\n"+
+			"counterexample (min): %s\nreplay: go test -run '%s' -rapidx.seed=%d -rapidx.example=%d %s%s%s",
+			seed, f.testIndex+1, f.steps, f.desc, full, seed, f.testIndex+1, cfg.sizeFlags(), stored, f.occurrences())
+	}
+	if cfg.StopOnFirstFailure {
+		t.FailNow()
+	}
 }
 
 // StateMachine represents a state machine for property-based testing.
@@ -283,33 +760,62 @@ type StateMachine[S, C any] struct {
 
 	// Commands defines the available commands that can be executed on the state machine.
 	Commands []Command[S, C]
+
+	// Invariant, when set, is checked on the state after every transition and
+	// returns an error describing any violation. Optional.
+	Invariant func(S) error
 }
 
 // Command represents a single command that can be executed on a state machine.
 type Command[S, C any] struct {
-	// Name is a human-readable name for the command.
+	// Name is a human-readable name for the command. Names identify the
+	// command that produced each step of a CommandSequence, so they must be
+	// unique within a StateMachine.
 	Name string
 
 	// Generator creates instances of the command.
 	Generator gen.Generator[C]
 
+	// StateGenerator, when set, is used instead of Generator and receives the
+	// model state at the step being generated, so command values can depend
+	// on it (e.g. withdraw amounts bounded by the balance).
+	StateGenerator func(S) gen.Generator[C]
+
+	// Weight is the relative frequency of the command among the commands
+	// eligible at a step. Zero means 1.
+	Weight float64
+
 	// Execute applies the command to the current state and returns the new state.
-	// If an error is returned, the command execution is considered failed.
+	// If an error is returned, the command execution is considered failed and
+	// the state is left unchanged.
 	Execute func(S, C) (S, error)
 
+	// ExpectError reports whether Execute is expected to fail for the given
+	// state and command (e.g. a withdrawal above the balance). When set, the
+	// test fails if an expected error does not occur; unexpected errors always
+	// fail the test. Postconditions are not checked on expected errors.
+	ExpectError func(S, C) bool
+
 	// Precondition determines if a command can be executed in the given state.
-	// Commands that don't meet their precondition are skipped during execution.
+	// Generated sequences only contain commands whose precondition holds at
+	// their step; commands that don't meet it are skipped during execution.
 	Precondition func(S, C) bool
 
 	// Postcondition validates that the command execution was correct.
 	// It receives the original state, the command, and the resulting state.
-	// If it returns false, the test fails.
+	// If it returns false, the test fails. It is only checked when Execute
+	// succeeds.
 	Postcondition func(S, C, S) bool
 }
 
 // CommandSequence represents a sequence of commands to be executed on a state machine.
 type CommandSequence[C any] struct {
 	Commands []C
+
+	// Names holds, for each element of Commands, the Name of the Command that
+	// produced it. Its Precondition, Execute and Postcondition are the ones
+	// applied to that step; steps without a known name are skipped.
+	Names []string
 }
 
 // StateMachineResult holds the result of executing a command sequence on a state machine.
@@ -326,6 +832,12 @@ type StateMachineResult[S, C any] struct {
 
 // StateTransition represents a single state transition in the execution history.
 type StateTransition[S, C any] struct {
+	// Step is the 1-based position of the command in the sequence.
+	Step int
+
+	// Name is the Name of the Command that was executed.
+	Name string
+
 	// Command is the command that was executed.
 	Command C
 
@@ -337,16 +849,89 @@ type StateTransition[S, C any] struct {
 
 	// Error is any error that occurred during command execution.
 	Error error
+
+	// ExpectedError reports whether the Command declared that an error was
+	// expected for FromState and Command.
+	ExpectedError bool
 }
 
 // commandSequenceGenerator creates a generator for command sequences.
 type commandSequenceGenerator[S, C any] struct {
 	stateMachine StateMachine[S, C]
+	minLength    int
 	maxLength    int
+
+	// minSize and maxSize, when maxSize > minSize, scale the maximum length
+	// with the size hint: from minLength at minSize to maxLength at maxSize.
+	// Both are zero with Config.FixedSteps.
+	minSize int
+	maxSize int
+}
+
+// sequenceRun returns the generator of the command sequences of sm and the
+// configuration to run them with, applying the state machine options of cfg.
+func sequenceRun[S, C any](sm StateMachine[S, C], cfg Config) (commandSequenceGenerator[S, C], Config) {
+	g := commandSequenceGenerator[S, C]{
+		stateMachine: sm,
+		minLength:    max(cfg.MinSteps, 0),
+		maxLength:    cfg.MaxSteps,
+	}
+	if !cfg.FixedSteps {
+		g.minSize, g.maxSize = cfg.sizeBounds()
+	}
+	if g.maxLength <= 0 {
+		g.maxLength = defaultMaxSteps
+	}
+	g.maxLength = max(g.maxLength, g.minLength)
+	if cfg.Sequences > 0 {
+		cfg.Examples = cfg.Sequences
+	}
+	return g, cfg
 }
 
+// commandTries is the number of values drawn for a command at a step before
+// it is considered ineligible there because its precondition keeps failing.
+const commandTries = 3
+
 // Generate implements the Generator interface for command sequences.
+// Generation runs the model alongside: each step chooses among the commands
+// whose precondition holds for the current state (by Weight), draws the
+// command value from the state, and executes it to get the next state. The
+// sequence ends early when no command is eligible. Shrinking is done by
+// sequenceShrinker.
 func (g commandSequenceGenerator[S, C]) Generate(r *rand.Rand, sz gen.Size) (CommandSequence[C], gen.Shrinker[CommandSequence[C]]) {
+	length := g.length(r, sz)
+
+	steps := make([]sequenceStep[S, C], 0, length)
+	state := g.stateMachine.InitialState
+	for len(steps) < length {
+		cmd, val, shrinker, ok := g.nextCommand(r, sz, state)
+		if !ok {
+			break
+		}
+		steps = append(steps, sequenceStep[S, C]{cmd: cmd, val: val, shrink: shrinker})
+
+		if cmd.Execute != nil {
+			if next, err := cmd.Execute(state, val); err == nil {
+				state = next
+			}
+		}
+	}
+
+	sequence := CommandSequence[C]{
+		Commands: make([]C, len(steps)),
+		Names:    make([]string, len(steps)),
+	}
+	for i, step := range steps {
+		sequence.Commands[i] = step.val
+		sequence.Names[i] = step.cmd.Name
+	}
+	return sequence, newSequenceShrinker(g.stateMachine, steps)
+}
+
+// length draws the number of commands of a sequence generated with sz:
+// between minLength and maxLength, the latter scaled with the size hint.
+func (g commandSequenceGenerator[S, C]) length(r *rand.Rand, sz gen.Size) int {
 	// Determine sequence length based on size constraints
 	maxLen := g.maxLength
 	if maxLen <= 0 {
@@ -356,73 +941,125 @@ func (g commandSequenceGenerator[S, C]) Generate(r *rand.Rand, sz gen.Size) (Com
 		}
 	}
 
-	// Generate a random length between 0 and maxLen
-	length := r.Intn(maxLen + 1)
+	// Scale the maximum length with the size hint
+	minLen := min(g.minLength, maxLen)
+	if g.maxSize > g.minSize && sz.Scale > 0 {
+		scale := min(max(sz.Scale, g.minSize), g.maxSize)
+		maxLen = minLen + (maxLen-minLen)*(scale-g.minSize)/(g.maxSize-g.minSize)
+	}
+
+	// Generate a random length between minLen and maxLen
+	return minLen + r.Intn(maxLen-minLen+1)
+}
 
-	commands := make([]C, length)
-	shrinkers := make([]gen.Shrinker[C], length)
+// nextCommand chooses the command of the next step for the given state and
+// draws its value. Commands are picked by weight; a command whose
+// precondition rejects commandTries values is dropped for this step.
+// It returns false when no command is eligible.
+func (g commandSequenceGenerator[S, C]) nextCommand(r *rand.Rand, sz gen.Size, state S) (*Command[S, C], C, gen.Shrinker[C], bool) {
+	candidates := make([]int, len(g.stateMachine.Commands))
+	tries := make([]int, len(g.stateMachine.Commands))
+	for i := range candidates {
+		candidates[i] = i
+	}
 
-	// Generate each command in the sequence
-	for i := 0; i < length; i++ {
-		// Select a random command type
-		if len(g.stateMachine.Commands) == 0 {
-			// No commands available, skip
-			continue
+	for len(candidates) > 0 {
+		k := pickWeighted(r, candidates, func(i int) float64 { return g.stateMachine.Commands[i].Weight })
+		cmd := &g.stateMachine.Commands[candidates[k]]
+
+		val, shrinker := commandGenerator(cmd, state).Generate(r, sz)
+		if cmd.Precondition == nil || cmd.Precondition(state, val) {
+			return cmd, val, shrinker, true
 		}
-		cmdIndex := r.Intn(len(g.stateMachine.Commands))
-		cmd := g.stateMachine.Commands[cmdIndex]
 
-		// Generate the command
-		cmdVal, cmdShrinker := cmd.Generator.Generate(r, sz)
-		commands[i] = cmdVal
-		shrinkers[i] = cmdShrinker
+		tries[candidates[k]]++
+		if tries[candidates[k]] >= commandTries {
+			candidates = append(candidates[:k], candidates[k+1:]...)
+		}
 	}
 
-	// If no commands were generated (because no commands are available), create empty sequence
-	if len(g.stateMachine.Commands) == 0 {
-		commands = make([]C, 0)
-		shrinkers = make([]gen.Shrinker[C], 0)
-	}
+	var z C
+	return nil, z, nil, false
+}
 
-	sequence := CommandSequence[C]{Commands: commands}
+// commandGenerator returns the generator of cmd's values at the given state.
+func commandGenerator[S, C any](cmd *Command[S, C], state S) gen.Generator[C] {
+	if cmd.StateGenerator != nil {
+		return cmd.StateGenerator(state)
+	}
+	return cmd.Generator
+}
 
-	// Create a shrinker for the sequence
-	shrinker := func(accept bool) (CommandSequence[C], bool) {
-		// Try different shrinking strategies
-		if len(commands) > 0 {
-			// Strategy 1: Remove commands from the end
-			newCommands := make([]C, len(commands)-1)
-			copy(newCommands, commands[:len(commands)-1])
-			newSequence := CommandSequence[C]{Commands: newCommands}
-			return newSequence, true
+// pickWeighted returns the position in candidates of an element chosen with
+// probability proportional to its weight (weights <= 0 count as 1).
+func pickWeighted(r *rand.Rand, candidates []int, weight func(int) float64) int {
+	total := 0.0
+	for _, c := range candidates {
+		total += effectiveWeight(weight(c))
+	}
+	x := r.Float64() * total
+	for k, c := range candidates {
+		x -= effectiveWeight(weight(c))
+		if x < 0 {
+			return k
 		}
+	}
+	return len(candidates) - 1
+}
 
-		// Strategy 2: Shrink individual commands
-		for i := len(commands) - 1; i >= 0; i-- {
-			if newCmd, ok := shrinkers[i](accept); ok {
-				newCommands := make([]C, len(commands))
-				copy(newCommands, commands)
-				newCommands[i] = newCmd
-				newSequence := CommandSequence[C]{Commands: newCommands}
-				return newSequence, true
-			}
+// effectiveWeight maps unset (zero or negative) weights to 1.
+func effectiveWeight(w float64) float64 {
+	if w <= 0 {
+		return 1
+	}
+	return w
+}
+
+// findMatchingCommand returns the command of sm with the given name, or nil.
+func findMatchingCommand[S, C any](sm StateMachine[S, C], name string) *Command[S, C] {
+	for i := range sm.Commands {
+		if sm.Commands[i].Name == name {
+			return &sm.Commands[i]
 		}
+	}
+	return nil
+}
 
-		return sequence, false
+// validateStateMachine panics if two commands of sm share a name, since
+// steps could not be dispatched to the command that produced them, or if a
+// command has no generator.
+func validateStateMachine[S, C any](sm StateMachine[S, C]) {
+	seen := make(map[string]bool, len(sm.Commands))
+	for _, cmd := range sm.Commands {
+		if seen[cmd.Name] {
+			panic(fmt.Sprintf("prop.TestStateMachine: duplicate command name %q", cmd.Name))
+		}
+		seen[cmd.Name] = true
+		if cmd.Generator == nil && cmd.StateGenerator == nil {
+			panic(fmt.Sprintf("prop.TestStateMachine: command %q has no Generator or StateGenerator", cmd.Name))
+		}
 	}
+}
 
-	return sequence, shrinker
+// stepName returns the name of the command that produced step i of sequence.
+func stepName[C any](sequence CommandSequence[C], i int) string {
+	if i < len(sequence.Names) {
+		return sequence.Names[i]
+	}
+	return ""
 }
 
-// findMatchingCommand finds a command that can handle the given command.
-// This is a simplified implementation - in practice you'd want proper command type discrimination.
-func findMatchingCommand[S, C any](sm StateMachine[S, C], cmd C) *Command[S, C] {
-	if len(sm.Commands) == 0 {
-		return nil
+// describeSequence formats a command sequence for failure reports, one
+// numbered step per line with the name of its command.
+func describeSequence[C any](sequence CommandSequence[C]) string {
+	if len(sequence.Commands) == 0 {
+		return "(no commands)"
 	}
-	// For simplicity, we'll use the first command that can handle the command
-	// In a real implementation, you might want to add command type discrimination
-	return &sm.Commands[0]
+	var b strings.Builder
+	for i, cmd := range sequence.Commands {
+		fmt.Fprintf(&b, "\n  %d. %s: %#v", i+1, stepName(sequence, i), cmd)
+	}
+	return b.String()
 }
 
 // executeStateMachine executes a command sequence on a state machine and returns the result.
@@ -431,12 +1068,12 @@ func executeStateMachine[S, C any](sm StateMachine[S, C], sequence CommandSequen
 	history := make([]StateTransition[S, C], 0, len(sequence.Commands))
 	skipped := make([]C, 0)
 
-	for _, cmd := range sequence.Commands {
-		// Find a command that can handle this command type
-		matchedCmd := findMatchingCommand(sm, cmd)
+	for i, cmd := range sequence.Commands {
+		// Dispatch to the command that produced this step
+		matchedCmd := findMatchingCommand(sm, stepName(sequence, i))
 
 		if matchedCmd == nil {
-			// No commands available, skip
+			// Unknown command, skip
 			skipped = append(skipped, cmd)
 			continue
 		}
@@ -453,10 +1090,13 @@ func executeStateMachine[S, C any](sm StateMachine[S, C], sequence CommandSequen
 
 		// Record the transition
 		transition := StateTransition[S, C]{
-			Command:   cmd,
-			FromState: fromState,
-			ToState:   newState,
-			Error:     err,
+			Step:          i + 1,
+			Name:          matchedCmd.Name,
+			Command:       cmd,
+			FromState:     fromState,
+			ToState:       newState,
+			Error:         err,
+			ExpectedError: matchedCmd.ExpectError != nil && matchedCmd.ExpectError(fromState, cmd),
 		}
 		history = append(history, transition)
 
@@ -475,39 +1115,76 @@ func executeStateMachine[S, C any](sm StateMachine[S, C], sequence CommandSequen
 
 // TestStateMachine tests a state machine using property-based testing.
 // It generates command sequences and validates that the state machine behaves correctly.
+// Each step is executed and checked with the Command that generated it, and
+// the Invariant of the machine is checked after every transition. Failures
+// report the first failing step, and the counterexample is printed as a
+// numbered trace of the shrunk sequence, followed by a regression test that
+// replays it on Config.StateMachineExpr (see StateMachineResult.GoTest).
 func TestStateMachine[S, C any](t *testing.T, sm StateMachine[S, C], cfg Config) {
-	// Create a generator for command sequences
-	seqGen := commandSequenceGenerator[S, C]{
-		stateMachine: sm,
-		maxLength:    20, // Default maximum sequence length
-	}
+	validateStateMachine(sm)
 
-	// Use the existing ForAll function to test the state machine
-	ForAll(t, cfg, seqGen)(func(t *testing.T, sequence CommandSequence[C]) {
+	seqGen, cfg := sequenceRun(sm, cfg)
+	forAll(t, cfg, seqGen, func(t *testing.T, sequence CommandSequence[C]) {
+		result := executeStateMachine(sm, sequence)
+		if i, err := checkHistory(sm, result.ExecutionHistory); err != nil {
+			tr := result.ExecutionHistory[i]
+			t.Errorf("step %d (%s %#v): %v", tr.Step, tr.Name, tr.Command, err)
+		}
+	}, func(sequence CommandSequence[C]) string {
 		result := executeStateMachine(sm, sequence)
+		return describeTrace(result.ExecutionHistory) +
+			"\nregression test:\n" + strings.TrimSuffix(result.GoTest(regressionName(t.Name()), cfg.StateMachineExpr), "\n")
+	})
+}
 
-		// Validate the execution result
-		for _, transition := range result.ExecutionHistory {
-			// Find the command that was executed
-			var executedCmd *Command[S, C]
-			for i := range sm.Commands {
-				// For simplicity, we'll assume the first command matches
-				// In a real implementation, you might want to add proper command matching
-				executedCmd = &sm.Commands[i]
-				break
-			}
+// checkHistory checks the transitions of history in order: their error
+// against the expectation of their command, the Postcondition of successful
+// commands and the Invariant of sm. It returns the index of the first failing
+// transition and why it failed.
+func checkHistory[S, C any](sm StateMachine[S, C], history []StateTransition[S, C]) (int, error) {
+	for i, tr := range history {
+		switch {
+		case tr.Error != nil && !tr.ExpectedError:
+			return i, fmt.Errorf("unexpected error: %w", tr.Error)
+		case tr.Error == nil && tr.ExpectedError:
+			return i, errors.New("expected an error, got none")
+		}
 
-			if executedCmd != nil && executedCmd.Postcondition != nil {
-				if !executedCmd.Postcondition(transition.FromState, transition.Command, transition.ToState) {
-					t.Errorf("postcondition failed for command %s: from %v, cmd %v, to %v",
-						executedCmd.Name, transition.FromState, transition.Command, transition.ToState)
+		state := tr.FromState
+		if tr.Error == nil {
+			state = tr.ToState
+			if cmd := findMatchingCommand(sm, tr.Name); cmd != nil && cmd.Postcondition != nil {
+				if !cmd.Postcondition(tr.FromState, tr.Command, tr.ToState) {
+					return i, errors.New("postcondition failed")
 				}
 			}
-
-			// Check that no unexpected errors occurred
-			if transition.Error != nil {
-				t.Errorf("unexpected error executing command %v: %v", transition.Command, transition.Error)
+		}
+		if sm.Invariant != nil {
+			if err := sm.Invariant(state); err != nil {
+				return i, fmt.Errorf("invariant violated: %w", err)
 			}
 		}
-	})
+	}
+	return -1, nil
+}
+
+// describeTrace formats the transitions of an execution as a numbered trace
+// with the state before and after each step.
+func describeTrace[S, C any](history []StateTransition[S, C]) string {
+	if len(history) == 0 {
+		return "(no commands)"
+	}
+	var b strings.Builder
+	for _, tr := range history {
+		fmt.Fprintf(&b, "\n  %d. %s: %#v\n     before: %+v\n     after:  %+v", tr.Step, tr.Name, tr.Command, tr.FromState, tr.ToState)
+		switch {
+		case tr.Error != nil && tr.ExpectedError:
+			fmt.Fprintf(&b, "\n     error:  %v (expected)", tr.Error)
+		case tr.Error != nil:
+			fmt.Fprintf(&b, "\n     error:  %v", tr.Error)
+		case tr.ExpectedError:
+			b.WriteString("\n     error:  none (expected one)")
+		}
+	}
+	return b.String()
 }
@@ -6,6 +6,8 @@ package prop
 import (
 	"fmt"
 	"math/rand"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -680,6 +682,15 @@ func TestDefault_UsesFlagValues(t *testing.T) {
 	if config.Parallelism != *flagParallelism {
 		t.Errorf("Default().Parallelism = %d, expected %d", config.Parallelism, *flagParallelism)
 	}
+
+	if config.MinSteps != *flagMinSteps || config.MaxSteps != *flagMaxSteps || config.Sequences != *flagSequences {
+		t.Errorf("Default() steps = %d..%d, sequences = %d, expected %d..%d, %d",
+			config.MinSteps, config.MaxSteps, config.Sequences, *flagMinSteps, *flagMaxSteps, *flagSequences)
+	}
+
+	if config.FixedSteps != *flagFixedSteps {
+		t.Errorf("Default().FixedSteps = %v, expected %v", config.FixedSteps, *flagFixedSteps)
+	}
 }
 
 // Test more comprehensive scenarios to increase coverage
@@ -1109,3 +1120,362 @@ func TestForAll_ParallelShrinkingBreakPath(t *testing.T) {
 		}
 	})
 }
+
+// TestConfig_exampleIndices tests the selection of examples to run.
+func TestConfig_exampleIndices(t *testing.T) {
+	all := slices.Collect(Config{Examples: 4}.exampleIndices(t))
+	if fmt.Sprint(all) != "[0 1 2 3]" {
+		t.Errorf("exampleIndices() = %v, expected [0 1 2 3]", all)
+	}
+
+	one := slices.Collect(Config{Examples: 4, Example: 7}.exampleIndices(t))
+	if fmt.Sprint(one) != "[6]" {
+		t.Errorf("exampleIndices() = %v, expected [6]", one)
+	}
+}
+
+// TestConfig_exampleIndicesDuration tests that a time budget keeps yielding
+// examples until it elapses, and that Example still selects a single one.
+func TestConfig_exampleIndicesDuration(t *testing.T) {
+	config := Config{Examples: 1, Duration: 20 * time.Millisecond}
+	start := time.Now()
+	n := 0
+	for i := range config.exampleIndices(t) {
+		if i != n {
+			t.Fatalf("Expected index %d, got %d", n, i)
+		}
+		n++
+		time.Sleep(time.Millisecond)
+	}
+	if n < 2 {
+		t.Errorf("Expected more examples than Config.Examples within the budget, got %d", n)
+	}
+	if elapsed := time.Since(start); elapsed < config.Duration {
+		t.Errorf("Expected the examples to stop after the budget, stopped after %v", elapsed)
+	}
+
+	config.Example = 3
+	if one := slices.Collect(config.exampleIndices(t)); fmt.Sprint(one) != "[2]" {
+		t.Errorf("exampleIndices() = %v, expected [2]", one)
+	}
+}
+
+// TestBudgetEnd tests that time budgets end before the deadline of the test.
+func TestBudgetEnd(t *testing.T) {
+	end := budgetEnd(t, time.Second)
+	if until := time.Until(end); until <= 0 || until > time.Second {
+		t.Errorf("Expected a budget of 1s to end within 1s, ends in %v", until)
+	}
+
+	end = budgetEnd(t, 0)
+	deadline, ok := t.Deadline()
+	if !ok && !end.IsZero() {
+		t.Errorf("Expected no limit without a budget or a deadline, got %v", end)
+	}
+	if ok && !end.Before(deadline) {
+		t.Errorf("Expected the budget to end before the test deadline %v, got %v", deadline, end)
+	}
+}
+
+// TestForAll_Duration tests that a time budget runs more examples than
+// Config.Examples, sequentially and in parallel, and that ex#K receives the
+// same input as in a run without budget.
+func TestForAll_Duration(t *testing.T) {
+	config := Config{Seed: 12345, Examples: 5, MaxShrink: 10, Parallelism: 1}
+	counted := collectExamples(t, config)
+
+	config.Duration = 10 * time.Millisecond
+	for _, parallelism := range []int{1, 4} {
+		config.Parallelism = parallelism
+		budget := collectExamples(t, config)
+		if len(budget) <= 5 {
+			t.Errorf("Expected more than 5 examples with parallelism %d, got %d", parallelism, len(budget))
+		}
+		for name, v := range counted {
+			if budget[name] != v {
+				t.Errorf("Expected %s to receive %d with parallelism %d, got %d", name, v, parallelism, budget[name])
+			}
+		}
+	}
+}
+
+// TestExampleSeed tests that example seeds are stable and distinct per index.
+func TestExampleSeed(t *testing.T) {
+	if exampleSeed(12345, 3) != exampleSeed(12345, 3) {
+		t.Error("exampleSeed() should be deterministic")
+	}
+
+	seen := make(map[int64]bool)
+	for i := 0; i < 100; i++ {
+		s := exampleSeed(12345, i)
+		if seen[s] {
+			t.Errorf("exampleSeed() generated duplicate seed for index %d", i)
+		}
+		seen[s] = true
+	}
+
+	if exampleSeed(1, 0) == exampleSeed(2, 0) {
+		t.Error("exampleSeed() should depend on the master seed")
+	}
+}
+
+// collectExamples runs ForAll and returns the value received by each ex#K subtest.
+func collectExamples(t *testing.T, config Config) map[string]int {
+	var mu sync.Mutex
+	values := make(map[string]int)
+	t.Run("run", func(t *testing.T) {
+		ForAll(t, config, gen.IntRange(0, 1_000_000))(func(st *testing.T, val int) {
+			mu.Lock()
+			defer mu.Unlock()
+			values[st.Name()[len(t.Name())+1:]] = val
+		})
+	})
+	return values
+}
+
+// TestForAll_ExamplesIndependentOfParallelism tests that ex#K receives the same
+// input whether examples run sequentially or in parallel.
+func TestForAll_ExamplesIndependentOfParallelism(t *testing.T) {
+	config := Config{
+		Seed:        12345,
+		Examples:    20,
+		MaxShrink:   10,
+		ShrinkStrat: "bfs",
+		Parallelism: 1,
+	}
+	sequential := collectExamples(t, config)
+
+	config.Parallelism = 4
+	parallel := collectExamples(t, config)
+
+	if len(sequential) != 20 || len(parallel) != 20 {
+		t.Fatalf("Expected 20 examples in each run, got %d and %d", len(sequential), len(parallel))
+	}
+	for name, v := range sequential {
+		if parallel[name] != v {
+			t.Errorf("%s: sequential value %d, parallel value %d", name, v, parallel[name])
+		}
+	}
+}
+
+// TestForAll_SingleExampleReplay tests that Config.Example regenerates only ex#K
+// with the same input it had in the full run.
+func TestForAll_SingleExampleReplay(t *testing.T) {
+	config := Config{
+		Seed:        12345,
+		Examples:    10,
+		MaxShrink:   10,
+		ShrinkStrat: "bfs",
+		Parallelism: 1,
+	}
+	full := collectExamples(t, config)
+
+	config.Example = 7
+	single := collectExamples(t, config)
+
+	if len(single) != 1 {
+		t.Fatalf("Expected a single example, got %v", single)
+	}
+	if v, ok := single["ex#7"]; !ok || v != full["ex#7"] {
+		t.Errorf("ex#7 = %d, expected %d", v, full["ex#7"])
+	}
+}
+
+// TestConfig_sizeFor tests the size ramp across the examples of a run.
+func TestConfig_sizeFor(t *testing.T) {
+	config := Config{Examples: 11, MinSize: 10, MaxSize: 20}
+
+	if got := config.sizeFor(0).Scale; got != 10 {
+		t.Errorf("sizeFor(0).Scale = %d, expected 10", got)
+	}
+	if got := config.sizeFor(5).Scale; got != 15 {
+		t.Errorf("sizeFor(5).Scale = %d, expected 15", got)
+	}
+	if got := config.sizeFor(10).Scale; got != 20 {
+		t.Errorf("sizeFor(10).Scale = %d, expected 20", got)
+	}
+	if got := config.sizeFor(99).Scale; got != 20 {
+		t.Errorf("sizeFor(99).Scale = %d, expected 20", got)
+	}
+
+	defaults := Config{Examples: 100}
+	if got := defaults.sizeFor(0).Scale; got != defaultMinSize {
+		t.Errorf("default sizeFor(0).Scale = %d, expected %d", got, defaultMinSize)
+	}
+	if got := defaults.sizeFor(99).Scale; got != defaultMaxSize {
+		t.Errorf("default sizeFor(99).Scale = %d, expected %d", got, defaultMaxSize)
+	}
+}
+
+// TestConfig_strategy tests the shrinking strategy passed to the generators.
+func TestConfig_strategy(t *testing.T) {
+	tests := map[string]string{"dfs": "dfs", "bfs": "bfs", "": "bfs", "invalid": "bfs"}
+	for strat, want := range tests {
+		cfg := Config{Examples: 3, ShrinkStrat: strat}
+		if got := cfg.sizeFor(1).Strategy; got != want {
+			t.Errorf("ShrinkStrat %q: sizeFor(1).Strategy = %q, expected %q", strat, got, want)
+		}
+	}
+}
+
+// TestForAll_StrategyPerRun tests that runs with different strategies pass
+// theirs to the generator without changing the default of package gen.
+func TestForAll_StrategyPerRun(t *testing.T) {
+	before := gen.GetShrinkStrategy()
+	for _, strat := range []string{gen.ShrinkStrategyDFS, gen.ShrinkStrategyBFS} {
+		t.Run(strat, func(t *testing.T) {
+			t.Parallel()
+			g := gen.From(func(r *rand.Rand, sz gen.Size) (int, gen.Shrinker[int]) {
+				if sz.Strategy != strat {
+					t.Errorf("Expected strategy %q, got %q", strat, sz.Strategy)
+				}
+				return gen.IntRange(0, 100).Generate(r, sz)
+			})
+			ForAll(t, Config{Seed: 1, Examples: 20, MaxShrink: 10, ShrinkStrat: strat}, g)(func(*testing.T, int) {})
+		})
+	}
+	t.Cleanup(func() {
+		if got := gen.GetShrinkStrategy(); got != before {
+			t.Errorf("Expected the default strategy to stay %q, got %q", before, got)
+		}
+	})
+}
+
+// TestForAll_SizeRamp tests that ForAll grows the size passed to the generator.
+func TestForAll_SizeRamp(t *testing.T) {
+	config := Config{
+		Seed:        12345,
+		Examples:    5,
+		MaxShrink:   10,
+		ShrinkStrat: "bfs",
+		Parallelism: 1,
+		MinSize:     2,
+		MaxSize:     10,
+	}
+
+	var scales []int
+	g := gen.From(func(r *rand.Rand, sz gen.Size) (int, gen.Shrinker[int]) {
+		scales = append(scales, sz.Scale)
+		return sz.Scale, func(accept bool) (int, bool) { return 0, false }
+	})
+	ForAll(t, config, g)(func(t *testing.T, val int) {})
+
+	if fmt.Sprint(scales) != "[2 4 6 8 10]" {
+		t.Errorf("Expected scales [2 4 6 8 10], got %v", scales)
+	}
+}
+
+// TestConfig_SizeFlags tests that the replay flags reproduce the size of every
+// example, including the step bounds of state machines when they are set.
+func TestConfig_SizeFlags(t *testing.T) {
+	tests := []struct {
+		cfg      Config
+		expected string
+	}{
+		{Config{Examples: 100}, "-rapidx.examples=100 -rapidx.minsize=1 -rapidx.maxsize=100"},
+		{Config{Examples: 5, MinSize: 2, MaxSize: 1}, "-rapidx.examples=5 -rapidx.minsize=2 -rapidx.maxsize=2"},
+		{Config{Examples: 5, MaxSteps: 20}, "-rapidx.examples=5 -rapidx.minsize=1 -rapidx.maxsize=100"},
+		{Config{Examples: 5, MinSteps: 3}, "-rapidx.examples=5 -rapidx.minsize=1 -rapidx.maxsize=100 -rapidx.steps.min=3 -rapidx.steps.max=20"},
+		{Config{Examples: 5, FixedSteps: true}, "-rapidx.examples=5 -rapidx.minsize=1 -rapidx.maxsize=100 -rapidx.steps.fixed"},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.sizeFlags(); got != tt.expected {
+			t.Errorf("Expected flags %q for %+v, got %q", tt.expected, tt.cfg, got)
+		}
+	}
+}
+
+// TestForAll2 tests that ForAll2 passes both generated arguments to the body.
+func TestForAll2(t *testing.T) {
+	config := Config{
+		Seed:        12345,
+		Examples:    20,
+		MaxShrink:   10,
+		ShrinkStrat: "bfs",
+		Parallelism: 1,
+	}
+
+	calls := 0
+	ForAll2(t, config, gen.IntRange(0, 10), gen.StringAlpha(gen.Size{Min: 1, Max: 5}))(func(t *testing.T, n int, s string) {
+		calls++
+		if n < 0 || n > 10 {
+			t.Errorf("n = %d, expected [0, 10]", n)
+		}
+		if len(s) < 1 || len(s) > 5 {
+			t.Errorf("s = %q, expected length [1, 5]", s)
+		}
+	})
+	if calls != 20 {
+		t.Errorf("Expected 20 calls, got %d", calls)
+	}
+}
+
+// TestForAll3 tests that ForAll3 passes the three generated arguments to the body.
+func TestForAll3(t *testing.T) {
+	config := Config{
+		Seed:        12345,
+		Examples:    20,
+		MaxShrink:   10,
+		ShrinkStrat: "bfs",
+		Parallelism: 4,
+	}
+
+	ForAll3(t, config, gen.IntRange(0, 10), gen.IntRange(20, 30), gen.Bool())(func(t *testing.T, a, b int, _ bool) {
+		if a >= b {
+			t.Errorf("Expected a < b, got %d and %d", a, b)
+		}
+	})
+}
+
+func TestDescribeArgs(t *testing.T) {
+	got := describeArgs(3, "x")
+	want := "\n  arg 1: 3\n  arg 2: \"x\""
+	if got != want {
+		t.Errorf("describeArgs() = %q, expected %q", got, want)
+	}
+}
+
+// TestFailureSet tests that failures with the same minimal counterexample or
+// the same panic site are recorded once, with the other examples that hit
+// them, while timeouts are only merged by counterexample.
+func TestFailureSet(t *testing.T) {
+	panicked := panicReason + "example.f (f.go:3)"
+	timedOut := timeoutReason + "1s"
+
+	s := newFailureSet()
+	s.add(failureResult{testIndex: 4, name: "ex#5", desc: "3"})
+	s.add(failureResult{testIndex: 1, name: "ex#2", desc: "3"})
+	s.add(failureResult{testIndex: 2, name: "ex#3", desc: "901", reason: panicked})
+	s.add(failureResult{testIndex: 7, name: "ex#8", desc: "950", reason: panicked})
+	s.add(failureResult{testIndex: 9, name: "ex#10", desc: "10"})
+	s.add(failureResult{testIndex: 13, name: "ex#14", desc: "17"})
+	s.add(failureResult{testIndex: 15, name: "ex#16", desc: "500", reason: timedOut})
+	s.add(failureResult{testIndex: 16, name: "ex#17", desc: "600", reason: timedOut})
+
+	if len(s.failures) != 6 {
+		t.Fatalf("Expected 6 distinct failures, got %d", len(s.failures))
+	}
+	if !s.duplicate(11, "10", "") {
+		t.Error("Expected a known minimal counterexample to be a duplicate")
+	}
+	if !s.duplicate(12, "999", panicked) {
+		t.Error("Expected a known panic site to be a duplicate")
+	}
+	if s.duplicate(17, "11", timedOut) {
+		t.Error("Expected an unknown counterexample that timed out not to be a duplicate")
+	}
+
+	expected := map[string]string{
+		"3":   "\nsame failure in: ex#2",
+		"901": "\nsame failure in: ex#8, ex#13",
+		"10":  "\nsame failure in: ex#12",
+		"17":  "",
+		"500": "",
+		"600": "",
+	}
+	for _, f := range s.failures {
+		if got := f.occurrences(); got != expected[f.desc] {
+			t.Errorf("Expected occurrences %q for %s, got %q", expected[f.desc], f.desc, got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package prop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// ReplayStateMachine executes sequence on sm from its InitialState and checks
+// every step like TestStateMachine does, reporting the first failing step and
+// the trace of the execution. It is the entry point of the regression tests
+// produced by StateMachineResult.GoTest. Steps whose name is not a command of
+// sm fail the test, so a replay against the wrong machine cannot pass.
+func ReplayStateMachine[S, C any](t *testing.T, sm StateMachine[S, C], sequence CommandSequence[C]) {
+	validateStateMachine(sm)
+	for i := range sequence.Commands {
+		if name := stepName(sequence, i); findMatchingCommand(sm, name) == nil {
+			t.Fatalf("step %d: unknown command %q", i+1, name)
+		}
+	}
+
+	result := executeStateMachine(sm, sequence)
+	if i, err := checkHistory(sm, result.ExecutionHistory); err != nil {
+		tr := result.ExecutionHistory[i]
+		t.Errorf("step %d (%s %#v): %v\ntrace:%s", tr.Step, tr.Name, tr.Command, err, describeTrace(result.ExecutionHistory))
+	}
+}
+
+// GoTest returns the Go source of a test function named name that replays the
+// executed commands of r with ReplayStateMachine, on the state machine built
+// by the Go expression machineExpr, e.g. "newBankMachine()". If machineExpr
+// is empty, the function declares the state machine as a variable to be
+// replaced by the one under test. Named types are written unqualified, for
+// the snippet to be pasted in the package that defines them.
+func (r StateMachineResult[S, C]) GoTest(name, machineExpr string) string {
+	stateType, cmdType := localType[S](), localType[C]()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", name)
+	if machineExpr == "" {
+		fmt.Fprintf(&b, "\tvar sm prop.StateMachine[%s, %s] // replace with the state machine under test\n", stateType.local, cmdType.local)
+		machineExpr = "sm"
+	}
+	fmt.Fprintf(&b, "\tprop.ReplayStateMachine(t, %s, prop.CommandSequence[%s]{\n", machineExpr, cmdType.local)
+	fmt.Fprintf(&b, "\t\tCommands: []%s{\n", cmdType.local)
+	for _, tr := range r.ExecutionHistory {
+		fmt.Fprintf(&b, "\t\t\t%s,\n", cmdType.literal(tr.Command))
+	}
+	b.WriteString("\t\t},\n\t\tNames: []string{")
+	for i, tr := range r.ExecutionHistory {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%q", tr.Name)
+	}
+	b.WriteString("},\n\t})\n}\n")
+	return b.String()
+}
+
+// goType holds the qualified and local spellings of a type.
+type goType struct {
+	qualified string
+	local     string
+}
+
+// localType returns the spellings of T. Named types of a package are written
+// without their package qualifier.
+func localType[T any]() goType {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	gt := goType{qualified: typ.String(), local: typ.String()}
+	if typ.PkgPath() != "" && typ.Name() != "" {
+		gt.local = typ.Name()
+	}
+	return gt
+}
+
+// literal formats v as a Go literal of the type.
+func (gt goType) literal(v any) string {
+	return strings.ReplaceAll(fmt.Sprintf("%#v", v), gt.qualified, gt.local)
+}
+
+// regressionName returns the name of the regression test of the test named
+// testName, e.g. TestBank/sub → TestBank_sub_Regression.
+func regressionName(testName string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, testName)
+	return name + "_Regression"
+}
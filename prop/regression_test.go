@@ -0,0 +1,107 @@
+package prop
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// regressionCmd is a named command type for the regression tests.
+type regressionCmd struct {
+	Kind   string
+	Amount int
+}
+
+// TestGoTest tests the source of the regression test of an execution.
+func TestGoTest(t *testing.T) {
+	seq := CommandSequence[int]{Commands: []int{5, 8}, Names: []string{"deposit", "withdraw"}}
+	got := executeStateMachine(accountMachine(false), seq).GoTest("TestAccount_Regression", "accountMachine(false)")
+	want := `func TestAccount_Regression(t *testing.T) {
+	prop.ReplayStateMachine(t, accountMachine(false), prop.CommandSequence[int]{
+		Commands: []int{
+			5,
+			8,
+		},
+		Names: []string{"deposit", "withdraw"},
+	})
+}
+`
+	if got != want {
+		t.Errorf("Expected source:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+// TestGoTest_NoExpression tests that, without a machine expression, the
+// regression test declares the state machine to replace.
+func TestGoTest_NoExpression(t *testing.T) {
+	seq := CommandSequence[int]{Commands: []int{5}, Names: []string{"deposit"}}
+	got := executeStateMachine(accountMachine(false), seq).GoTest("TestAccount_Regression", "")
+
+	if !strings.Contains(got, "\tvar sm prop.StateMachine[int, int] // replace with the state machine under test\n") {
+		t.Errorf("Expected the state machine declaration, got:\n%s", got)
+	}
+	if !strings.Contains(got, "prop.ReplayStateMachine(t, sm, ") {
+		t.Errorf("Expected the replay of sm, got:\n%s", got)
+	}
+}
+
+// TestGoTest_NamedTypes tests that named types are written unqualified and
+// that the source parses.
+func TestGoTest_NamedTypes(t *testing.T) {
+	sm := StateMachine[regressionCmd, regressionCmd]{
+		Commands: []Command[regressionCmd, regressionCmd]{{
+			Name:      "set",
+			Generator: gen.Const(regressionCmd{}),
+			Execute:   func(_, c regressionCmd) (regressionCmd, error) { return c, nil },
+		}},
+	}
+	seq := CommandSequence[regressionCmd]{
+		Commands: []regressionCmd{{Kind: "a\"b", Amount: 3}},
+		Names:    []string{"set"},
+	}
+	src := executeStateMachine(sm, seq).GoTest("TestSet_Regression", "newSetMachine()")
+
+	if strings.Contains(src, "prop.regressionCmd") {
+		t.Errorf("Expected unqualified types, got:\n%s", src)
+	}
+	if !strings.Contains(src, `regressionCmd{Kind:"a\"b", Amount:3}`) {
+		t.Errorf("Expected the command literal, got:\n%s", src)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "regression_test.go", "package prop\n\n"+src, 0); err != nil {
+		t.Errorf("Expected valid Go source, got %v:\n%s", err, src)
+	}
+}
+
+// TestReplayStateMachine tests replaying a sequence as a regression test.
+func TestReplayStateMachine(t *testing.T) {
+	seq := CommandSequence[int]{Commands: []int{5, 8, 3}, Names: []string{"deposit", "withdraw", "withdraw"}}
+
+	ReplayStateMachine(t, accountMachine(false), seq)
+
+	if failed := runCaptured(func(t *testing.T) { ReplayStateMachine(t, accountMachine(true), seq) }); !failed {
+		t.Error("Expected the missing error to fail the replay")
+	}
+
+	// a replay against a machine without the commands must not pass
+	var empty StateMachine[int, int]
+	if failed := runCaptured(func(t *testing.T) { ReplayStateMachine(t, empty, seq) }); !failed {
+		t.Error("Expected unknown commands to fail the replay")
+	}
+}
+
+// TestRegressionName tests the names of regression tests.
+func TestRegressionName(t *testing.T) {
+	tests := map[string]string{
+		"TestBank":           "TestBank_Regression",
+		"TestBank/sub#01":    "TestBank_sub_01_Regression",
+		"TestBank/with_case": "TestBank_with_case_Regression",
+	}
+	for in, want := range tests {
+		if got := regressionName(in); got != want {
+			t.Errorf("regressionName(%q) = %q, expected %q", in, got, want)
+		}
+	}
+}
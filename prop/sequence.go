@@ -0,0 +1,210 @@
+package prop
+
+import (
+	"fmt"
+
+	"github.com/lucaskalb/rapidx/gen"
+)
+
+// sequenceStep is one generated step of a command sequence, with the shrinker
+// of its command value.
+type sequenceStep[S, C any] struct {
+	cmd    *Command[S, C]
+	val    C
+	shrink gen.Shrinker[C]
+}
+
+// sequenceShrinker shrinks a generated command sequence in two phases:
+//
+//  1. Remove steps: chunks (half, quarter, ...) and then single steps, from
+//     anywhere in the sequence, largest removals first. After a removal the
+//     remaining steps are replayed on the model, and steps whose precondition
+//     no longer holds are dropped too, so candidates are always valid.
+//  2. Shrink the command values, one step at a time (first step first), with
+//     the shrinkers of their generators. Candidates that break the
+//     precondition of any step are skipped.
+//
+// Smaller values can make further removals possible, so both phases are
+// repeated until a round of value shrinking makes no progress.
+type sequenceShrinker[S, C any] struct {
+	sm    StateMachine[S, C]
+	steps []sequenceStep[S, C]
+
+	// cur holds the indices of the kept steps; vals their current values.
+	cur  []int
+	vals []C
+
+	// phase 1 state
+	queue [][]int
+	seen  map[string]struct{}
+	last  []int
+
+	// phase 2 state: the position in cur being shrunk and the pending value
+	pos      int
+	pending  C
+	proposed bool
+	removing bool
+	progress bool
+}
+
+// newSequenceShrinker returns the shrinker of the sequence made of steps.
+func newSequenceShrinker[S, C any](sm StateMachine[S, C], steps []sequenceStep[S, C]) gen.Shrinker[CommandSequence[C]] {
+	s := &sequenceShrinker[S, C]{
+		sm:    sm,
+		steps: steps,
+		cur:   make([]int, len(steps)),
+		vals:  make([]C, len(steps)),
+	}
+	for i := range steps {
+		s.cur[i] = i
+		s.vals[i] = steps[i].val
+	}
+	s.restart()
+	return s.next
+}
+
+// restart begins a round of removals from the current sequence.
+func (s *sequenceShrinker[S, C]) restart() {
+	s.removing, s.pos, s.progress, s.last = true, 0, false, nil
+	s.seen = map[string]struct{}{fmt.Sprint(s.cur): {}}
+	s.grow()
+}
+
+// next implements gen.Shrinker.
+func (s *sequenceShrinker[S, C]) next(accept bool) (CommandSequence[C], bool) {
+	if s.removing {
+		if accept && s.last != nil {
+			s.cur = s.last
+			s.grow()
+		}
+		if len(s.queue) > 0 {
+			s.last, s.queue = s.queue[0], s.queue[1:]
+			var none C
+			return s.build(s.last, -1, none), true
+		}
+		// the last removal was either accepted (rebased above) or rejected
+		s.removing, accept = false, false
+	}
+
+	if accept && s.proposed {
+		s.vals[s.cur[s.pos]] = s.pending
+		s.progress = true
+	}
+	for s.pos < len(s.cur) {
+		i := s.cur[s.pos]
+		for shrink := s.steps[i].shrink; shrink != nil; {
+			v, ok := shrink(accept)
+			if !ok {
+				break
+			}
+			accept = false
+			if !s.valid(i, v) {
+				continue
+			}
+			s.pending, s.proposed = v, true
+			return s.build(s.cur, i, v), true
+		}
+		// step exhausted → move to the next one, starting fresh
+		s.pos++
+		accept, s.proposed = false, false
+	}
+	if s.progress {
+		// the last value candidate was rejected or already applied
+		s.restart()
+		return s.next(false)
+	}
+	return CommandSequence[C]{}, false
+}
+
+// grow queues the removal candidates of the current sequence.
+func (s *sequenceShrinker[S, C]) grow() {
+	s.queue = s.queue[:0]
+	L := len(s.cur)
+	push := func(i, j int) {
+		idx := make([]int, 0, L-(j-i))
+		idx = append(idx, s.cur[:i]...)
+		idx = append(idx, s.cur[j:]...)
+		idx = s.normalize(idx)
+		k := fmt.Sprint(idx)
+		if _, ok := s.seen[k]; ok {
+			return
+		}
+		s.seen[k] = struct{}{}
+		s.queue = append(s.queue, idx)
+	}
+	// (1) remove chunks (binary: half, quarter, ...)
+	for chunk := L / 2; chunk >= 1; chunk /= 2 {
+		for i := 0; i+chunk <= L; i += chunk {
+			push(i, i+chunk)
+		}
+	}
+	// (2) remove single steps (R->L)
+	for i := L - 1; i >= 0; i-- {
+		push(i, i+1)
+	}
+}
+
+// normalize replays the steps idx on the model and drops those whose
+// precondition does not hold at their position.
+func (s *sequenceShrinker[S, C]) normalize(idx []int) []int {
+	out := idx[:0:0]
+	state := s.sm.InitialState
+	for _, i := range idx {
+		next, ok := s.apply(state, i, s.vals[i])
+		if ok {
+			out = append(out, i)
+			state = next
+		}
+	}
+	return out
+}
+
+// valid reports whether replacing the value of step i by v keeps the
+// precondition of every kept step satisfied.
+func (s *sequenceShrinker[S, C]) valid(i int, v C) bool {
+	state := s.sm.InitialState
+	for _, j := range s.cur {
+		val := s.vals[j]
+		if j == i {
+			val = v
+		}
+		next, ok := s.apply(state, j, val)
+		if !ok {
+			return false
+		}
+		state = next
+	}
+	return true
+}
+
+// apply checks the precondition of step i with value v at state and returns
+// the state after executing it on the model.
+func (s *sequenceShrinker[S, C]) apply(state S, i int, v C) (S, bool) {
+	cmd := s.steps[i].cmd
+	if cmd.Precondition != nil && !cmd.Precondition(state, v) {
+		return state, false
+	}
+	if cmd.Execute != nil {
+		if next, err := cmd.Execute(state, v); err == nil {
+			return next, true
+		}
+	}
+	return state, true
+}
+
+// build assembles the sequence of steps idx, with the value of step
+// override replaced by v (override -1 replaces nothing).
+func (s *sequenceShrinker[S, C]) build(idx []int, override int, v C) CommandSequence[C] {
+	seq := CommandSequence[C]{
+		Commands: make([]C, len(idx)),
+		Names:    make([]string, len(idx)),
+	}
+	for k, i := range idx {
+		seq.Commands[k] = s.vals[i]
+		if i == override {
+			seq.Commands[k] = v
+		}
+		seq.Names[k] = s.steps[i].cmd.Name
+	}
+	return seq
+}
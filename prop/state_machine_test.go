@@ -2,7 +2,9 @@ package prop
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/lucaskalb/rapidx/gen"
@@ -180,24 +182,121 @@ func TestExecuteStateMachine(t *testing.T) {
 	}
 
 	sequence := CommandSequence[string]{
-		Commands: []string{"inc", "inc", "dec", "inc"},
+		Commands: []string{"dec", "inc", "inc", "dec", "inc"},
+		Names:    []string{"decrement", "increment", "increment", "decrement", "increment"},
 	}
 
 	result := executeStateMachine(sm, sequence)
 
-	// With the current implementation, all commands will be executed using the first command
-	// The precondition check will determine if commands are skipped
+	// Each step runs the command that produced it; the first decrement fails
+	// its precondition at state 0 and is skipped
 	if len(result.ExecutionHistory) != 4 {
 		t.Errorf("Expected 4 executed commands, got %d", len(result.ExecutionHistory))
 	}
 
-	if len(result.SkippedCommands) != 0 {
-		t.Errorf("Expected 0 skipped commands, got %d", len(result.SkippedCommands))
+	if len(result.SkippedCommands) != 1 {
+		t.Errorf("Expected 1 skipped command, got %d", len(result.SkippedCommands))
+	}
+
+	// Final state should be 2 (0 + 1 + 1 - 1 + 1)
+	if result.FinalState != 2 {
+		t.Errorf("Expected final state 2, got %d", result.FinalState)
+	}
+
+	wantNames := []string{"increment", "increment", "decrement", "increment"}
+	for i, tr := range result.ExecutionHistory {
+		if tr.Name != wantNames[i] {
+			t.Errorf("Transition %d: expected command %s, got %s", i, wantNames[i], tr.Name)
+		}
+	}
+}
+
+// TestExecuteStateMachineUnknownName tests that steps without a known command name are skipped.
+func TestExecuteStateMachineUnknownName(t *testing.T) {
+	sm := StateMachine[int, string]{
+		InitialState: 0,
+		Commands: []Command[int, string]{
+			{
+				Name:      "increment",
+				Generator: gen.Const("inc"),
+				Execute: func(state int, cmd string) (int, error) {
+					return state + 1, nil
+				},
+			},
+		},
+	}
+
+	sequence := CommandSequence[string]{
+		Commands: []string{"inc", "inc", "inc"},
+		Names:    []string{"increment", "missing"},
+	}
+
+	result := executeStateMachine(sm, sequence)
+
+	if result.FinalState != 1 {
+		t.Errorf("Expected final state 1, got %d", result.FinalState)
+	}
+
+	if len(result.SkippedCommands) != 2 {
+		t.Errorf("Expected 2 skipped commands, got %d", len(result.SkippedCommands))
+	}
+}
+
+// TestStateMachineDuplicateNames tests that duplicate command names are rejected.
+func TestStateMachineDuplicateNames(t *testing.T) {
+	sm := StateMachine[int, string]{
+		Commands: []Command[int, string]{
+			{Name: "same", Generator: gen.Const("a")},
+			{Name: "same", Generator: gen.Const("b")},
+		},
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected TestStateMachine to panic on duplicate command names")
+		}
+	}()
+	TestStateMachine(t, sm, Config{Examples: 1})
+}
+
+// TestStateMachineDispatch tests that TestStateMachine applies each command's own
+// Execute and Postcondition.
+func TestStateMachineDispatch(t *testing.T) {
+	executed := map[string]int{}
+	checked := map[string]int{}
+	command := func(name string, delta int) Command[int, int] {
+		return Command[int, int]{
+			Name:      name,
+			Generator: gen.Const(delta),
+			Execute: func(state int, d int) (int, error) {
+				executed[name]++
+				return state + d, nil
+			},
+			Postcondition: func(from int, d int, to int) bool {
+				checked[name]++
+				return d == delta && to == from+d
+			},
+		}
+	}
+	sm := StateMachine[int, int]{
+		Commands: []Command[int, int]{command("up", 1), command("down", -1)},
+	}
+
+	TestStateMachine(t, sm, Config{Seed: 1, Examples: 20, MaxShrink: 10, ShrinkStrat: "bfs", Parallelism: 1})
+
+	for _, name := range []string{"up", "down"} {
+		if executed[name] == 0 || checked[name] == 0 {
+			t.Errorf("Command %s: executed %d times, postcondition checked %d times", name, executed[name], checked[name])
+		}
 	}
+}
 
-	// Final state should be 4 (0 + 1 + 1 + 1 + 1 = 4) since all inc commands are executed
-	if result.FinalState != 4 {
-		t.Errorf("Expected final state 4, got %d", result.FinalState)
+// TestDescribeSequence tests the failure report format of command sequences.
+func TestDescribeSequence(t *testing.T) {
+	got := describeSequence(CommandSequence[int]{Commands: []int{5, 7}, Names: []string{"push", "pop"}})
+	want := "\n  1. push: 5\n  2. pop: 7"
+	if got != want {
+		t.Errorf("describeSequence() = %q, expected %q", got, want)
 	}
 }
 
@@ -221,6 +320,7 @@ func TestExecuteStateMachineWithErrors(t *testing.T) {
 
 	sequence := CommandSequence[string]{
 		Commands: []string{"inc", "inc", "inc", "inc", "inc", "inc"}, // 6 increments
+		Names:    []string{"increment", "increment", "increment", "increment", "increment", "increment"},
 	}
 
 	result := executeStateMachine(sm, sequence)
@@ -424,3 +524,454 @@ func TestCommandSequenceGeneratorSizeConstraints(t *testing.T) {
 		}
 	}
 }
+
+// TestCommandSequenceGeneratorStateAware tests that generated steps satisfy their
+// preconditions and that command values can depend on the model state.
+func TestCommandSequenceGeneratorStateAware(t *testing.T) {
+	sm := StateMachine[int, int]{
+		InitialState: 0,
+		Commands: []Command[int, int]{
+			{
+				Name:      "deposit",
+				Generator: gen.IntRange(1, 100),
+				Execute: func(balance int, amount int) (int, error) {
+					return balance + amount, nil
+				},
+			},
+			{
+				Name: "withdraw",
+				StateGenerator: func(balance int) gen.Generator[int] {
+					return gen.IntRange(1, max(balance, 1))
+				},
+				Execute: func(balance int, amount int) (int, error) {
+					return balance - amount, nil
+				},
+				Precondition: func(balance int, amount int) bool {
+					return amount <= balance
+				},
+			},
+		},
+	}
+
+	g := commandSequenceGenerator[int, int]{stateMachine: sm, maxLength: 30}
+	r := rand.New(rand.NewSource(7))
+	withdrawals := 0
+	for i := 0; i < 50; i++ {
+		seq, _ := g.Generate(r, gen.Size{})
+		result := executeStateMachine(sm, seq)
+		if len(result.SkippedCommands) != 0 {
+			t.Fatalf("Expected no skipped commands, got %v", result.SkippedCommands)
+		}
+		if result.FinalState < 0 {
+			t.Fatalf("Balance went negative: %s", describeSequence(seq))
+		}
+		for _, name := range seq.Names {
+			if name == "withdraw" {
+				withdrawals++
+			}
+		}
+	}
+	if withdrawals == 0 {
+		t.Error("Expected some withdrawals to be generated")
+	}
+}
+
+// TestCommandSequenceGeneratorNoEligibleCommand tests that a sequence ends early
+// when no command satisfies its precondition.
+func TestCommandSequenceGeneratorNoEligibleCommand(t *testing.T) {
+	sm := StateMachine[int, string]{
+		InitialState: 0,
+		Commands: []Command[int, string]{
+			{
+				Name:      "once",
+				Generator: gen.Const("once"),
+				Execute: func(state int, _ string) (int, error) {
+					return state + 1, nil
+				},
+				Precondition: func(state int, _ string) bool {
+					return state == 0
+				},
+			},
+		},
+	}
+
+	g := commandSequenceGenerator[int, string]{stateMachine: sm, maxLength: 10}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		seq, _ := g.Generate(r, gen.Size{})
+		if len(seq.Commands) > 1 {
+			t.Fatalf("Expected at most one command, got %v", seq.Commands)
+		}
+	}
+}
+
+// TestCommandSequenceGeneratorWeights tests that commands are chosen by weight.
+func TestCommandSequenceGeneratorWeights(t *testing.T) {
+	sm := StateMachine[int, string]{
+		Commands: []Command[int, string]{
+			{Name: "common", Generator: gen.Const("c"), Weight: 9},
+			{Name: "rare", Generator: gen.Const("r")},
+		},
+	}
+
+	g := commandSequenceGenerator[int, string]{stateMachine: sm, maxLength: 50}
+	r := rand.New(rand.NewSource(3))
+	counts := map[string]int{}
+	for i := 0; i < 50; i++ {
+		seq, _ := g.Generate(r, gen.Size{})
+		for _, name := range seq.Names {
+			counts[name]++
+		}
+	}
+	if counts["common"] < 5*counts["rare"] {
+		t.Errorf("Expected common to be chosen about 9 times as often as rare, got %v", counts)
+	}
+}
+
+// shrinkSequence drives a sequence shrinker like the runner does, accepting
+// the candidates for which fails holds.
+func shrinkSequence[C any](seq CommandSequence[C], s gen.Shrinker[CommandSequence[C]], fails func(CommandSequence[C]) bool) CommandSequence[C] {
+	min := seq
+	accept := true
+	for i := 0; i < 10000; i++ {
+		next, ok := s(accept)
+		if !ok {
+			break
+		}
+		accept = fails(next)
+		if accept {
+			min = next
+		}
+	}
+	return min
+}
+
+// TestCommandSequenceShrinking tests that sequences shrink to a minimal failing
+// trace: unrelated steps removed from anywhere and payloads shrunk.
+func TestCommandSequenceShrinking(t *testing.T) {
+	sm := StateMachine[int, int]{
+		Commands: []Command[int, int]{
+			{
+				Name:      "add",
+				Generator: gen.IntRange(0, 100),
+				Execute: func(state int, v int) (int, error) {
+					return state + v, nil
+				},
+			},
+			{
+				Name:      "noop",
+				Generator: gen.IntRange(0, 100),
+				Execute: func(state int, _ int) (int, error) {
+					return state, nil
+				},
+			},
+		},
+	}
+	// fails while some add step has a payload of at least 50
+	fails := func(seq CommandSequence[int]) bool {
+		for i, name := range seq.Names {
+			if name == "add" && seq.Commands[i] >= 50 {
+				return true
+			}
+		}
+		return false
+	}
+
+	g := commandSequenceGenerator[int, int]{stateMachine: sm, maxLength: 30}
+	r := rand.New(rand.NewSource(11))
+	found := 0
+	for i := 0; i < 50 && found < 5; i++ {
+		seq, s := g.Generate(r, gen.Size{})
+		if !fails(seq) || len(seq.Commands) < 3 {
+			continue
+		}
+		found++
+		min := shrinkSequence(seq, s, fails)
+		if len(min.Commands) != 1 || min.Names[0] != "add" || min.Commands[0] > 55 {
+			t.Errorf("Expected a single add close to 50, got %s", describeSequence(min))
+		}
+	}
+	if found == 0 {
+		t.Fatal("Expected at least one failing sequence")
+	}
+}
+
+// TestCommandSequenceShrinkingKeepsPreconditions tests that every candidate
+// proposed by the shrinker satisfies the preconditions of its steps.
+func TestCommandSequenceShrinkingKeepsPreconditions(t *testing.T) {
+	sm := StateMachine[int, int]{
+		Commands: []Command[int, int]{
+			{
+				Name:      "deposit",
+				Generator: gen.IntRange(1, 100),
+				Execute: func(balance int, v int) (int, error) {
+					return balance + v, nil
+				},
+			},
+			{
+				Name: "withdraw",
+				StateGenerator: func(balance int) gen.Generator[int] {
+					return gen.IntRange(1, max(balance, 1))
+				},
+				Execute: func(balance int, v int) (int, error) {
+					return balance - v, nil
+				},
+				Precondition: func(balance int, v int) bool {
+					return v <= balance
+				},
+			},
+		},
+	}
+
+	g := commandSequenceGenerator[int, int]{stateMachine: sm, maxLength: 20}
+	r := rand.New(rand.NewSource(5))
+	for i := 0; i < 20; i++ {
+		seq, s := g.Generate(r, gen.Size{})
+		min := shrinkSequence(seq, s, func(c CommandSequence[int]) bool {
+			result := executeStateMachine(sm, c)
+			if len(result.SkippedCommands) != 0 {
+				t.Fatalf("Candidate breaks a precondition: %s", describeSequence(c))
+			}
+			// keep shrinking while there is at least one withdrawal
+			for _, name := range c.Names {
+				if name == "withdraw" {
+					return true
+				}
+			}
+			return false
+		})
+		for _, name := range seq.Names {
+			if name == "withdraw" && len(min.Commands) != 2 {
+				t.Errorf("Expected a deposit and a withdrawal, got %s", describeSequence(min))
+				break
+			}
+		}
+	}
+}
+
+// boundedCounter returns a counter machine whose invariant is violated when
+// the value exceeds limit.
+func boundedCounter(limit int) StateMachine[int, int] {
+	return StateMachine[int, int]{
+		Commands: []Command[int, int]{
+			{
+				Name:      "inc",
+				Generator: gen.IntRange(1, 5),
+				Execute:   func(s, d int) (int, error) { return s + d, nil },
+			},
+		},
+		Invariant: func(s int) error {
+			if s > limit {
+				return fmt.Errorf("value %d exceeds %d", s, limit)
+			}
+			return nil
+		},
+	}
+}
+
+// TestCheckHistory tests that the first failing transition is reported with
+// the check that failed.
+func TestCheckHistory(t *testing.T) {
+	sm := boundedCounter(5)
+	sm.Commands[0].Postcondition = func(from, d, to int) bool { return d != 4 }
+	sm.Commands = append(sm.Commands, Command[int, int]{
+		Name:      "fail",
+		Generator: gen.Const(0),
+		Execute:   func(s, _ int) (int, error) { return s, errors.New("boom") },
+	})
+
+	tests := []struct {
+		name  string
+		seq   CommandSequence[int]
+		index int
+		want  string
+	}{
+		{"passes", CommandSequence[int]{Commands: []int{1, 2}, Names: []string{"inc", "inc"}}, -1, ""},
+		{"invariant", CommandSequence[int]{Commands: []int{3, 3, 1}, Names: []string{"inc", "inc", "inc"}}, 1, "invariant violated: value 6 exceeds 5"},
+		{"postcondition", CommandSequence[int]{Commands: []int{1, 4}, Names: []string{"inc", "inc"}}, 1, "postcondition failed"},
+		{"error", CommandSequence[int]{Commands: []int{1, 0}, Names: []string{"inc", "fail"}}, 1, "unexpected error: boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, err := checkHistory(sm, executeStateMachine(sm, tt.seq).ExecutionHistory)
+			if i != tt.index {
+				t.Errorf("Expected failing index %d, got %d", tt.index, i)
+			}
+			if got := fmt.Sprint(err); err != nil && got != tt.want || err == nil && tt.want != "" {
+				t.Errorf("Expected error %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestDescribeTrace tests the numbered trace of an execution.
+func TestDescribeTrace(t *testing.T) {
+	sm := boundedCounter(5)
+	seq := CommandSequence[int]{Commands: []int{2, 4}, Names: []string{"inc", "inc"}}
+	want := "\n  1. inc: 2\n     before: 0\n     after:  2" +
+		"\n  2. inc: 4\n     before: 2\n     after:  6"
+	if got := describeTrace(executeStateMachine(sm, seq).ExecutionHistory); got != want {
+		t.Errorf("Expected trace %q, got %q", want, got)
+	}
+	if got := describeTrace[int, int](nil); got != "(no commands)" {
+		t.Errorf("Expected %q for an empty trace, got %q", "(no commands)", got)
+	}
+}
+
+// TestStateMachineInvariant tests that an invariant holding after every
+// transition passes.
+func TestStateMachineInvariant(t *testing.T) {
+	TestStateMachine(t, boundedCounter(1000), Config{Seed: 1, Examples: 50, MaxShrink: 100, StopOnFirstFailure: true})
+}
+
+// TestSequenceRun tests how the state machine options of a Config apply.
+func TestSequenceRun(t *testing.T) {
+	sm := boundedCounter(1000)
+
+	g, cfg := sequenceRun(sm, Config{Examples: 100})
+	if g.minLength != 0 || g.maxLength != defaultMaxSteps {
+		t.Errorf("Expected default steps 0..%d, got %d..%d", defaultMaxSteps, g.minLength, g.maxLength)
+	}
+	if g.minSize != defaultMinSize || g.maxSize != defaultMaxSize {
+		t.Errorf("Expected the default size range, got %d..%d", g.minSize, g.maxSize)
+	}
+	if cfg.Examples != 100 {
+		t.Errorf("Expected Examples to be kept without Sequences, got %d", cfg.Examples)
+	}
+
+	g, cfg = sequenceRun(sm, Config{Examples: 100, MinSteps: 30, MaxSteps: 10, Sequences: 7})
+	if g.minLength != 30 || g.maxLength != 30 {
+		t.Errorf("Expected MaxSteps raised to MinSteps, got %d..%d", g.minLength, g.maxLength)
+	}
+	if cfg.Examples != 7 {
+		t.Errorf("Expected Sequences to replace Examples, got %d", cfg.Examples)
+	}
+
+	g, _ = sequenceRun(sm, Config{Examples: 100, FixedSteps: true})
+	if g.minSize != 0 || g.maxSize != 0 {
+		t.Errorf("Expected no size range with FixedSteps, got %d..%d", g.minSize, g.maxSize)
+	}
+}
+
+// TestCommandSequenceGeneratorFixedLength tests that, with FixedSteps, the
+// first sequence can reach MaxSteps.
+func TestCommandSequenceGeneratorFixedLength(t *testing.T) {
+	cfg := Config{Examples: 100, MaxSteps: 10, FixedSteps: true}
+	g, cfg := sequenceRun(boundedCounter(1000), cfg)
+	n := 0
+	for i := 0; i < 200; i++ {
+		seq, _ := g.Generate(rand.New(rand.NewSource(int64(i))), cfg.sizeFor(0))
+		n = max(n, len(seq.Commands))
+	}
+	if n != 10 {
+		t.Errorf("Expected up to 10 commands in the first sequence, got up to %d", n)
+	}
+}
+
+// TestCommandSequenceGeneratorMinLength tests that sequences have at least
+// minLength commands while a command is eligible.
+func TestCommandSequenceGeneratorMinLength(t *testing.T) {
+	g := commandSequenceGenerator[int, int]{stateMachine: boundedCounter(1000), minLength: 5, maxLength: 8}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		seq, _ := g.Generate(r, gen.Size{})
+		if n := len(seq.Commands); n < 5 || n > 8 {
+			t.Fatalf("Expected between 5 and 8 commands, got %d", n)
+		}
+	}
+}
+
+// TestCommandSequenceGeneratorScaledLength tests that the maximum length
+// grows with the size hint.
+func TestCommandSequenceGeneratorScaledLength(t *testing.T) {
+	g := commandSequenceGenerator[int, int]{stateMachine: boundedCounter(1000), minLength: 2, maxLength: 40, minSize: 1, maxSize: 100}
+	r := rand.New(rand.NewSource(1))
+
+	longest := func(scale int) int {
+		n := 0
+		for i := 0; i < 200; i++ {
+			seq, _ := g.Generate(r, gen.Size{Scale: scale})
+			n = max(n, len(seq.Commands))
+		}
+		return n
+	}
+	if n := longest(1); n != 2 {
+		t.Errorf("Expected exactly 2 commands at the smallest size, got up to %d", n)
+	}
+	if n := longest(50); n < 10 || n > 21 {
+		t.Errorf("Expected at most 21 commands at size 50, got up to %d", n)
+	}
+	if n := longest(100); n <= 21 || n > 40 {
+		t.Errorf("Expected up to 40 commands at the largest size, got up to %d", n)
+	}
+}
+
+// accountMachine returns a balance machine whose withdrawals above the
+// balance are expected to fail.
+func accountMachine(bug bool) StateMachine[int, int] {
+	return StateMachine[int, int]{
+		Commands: []Command[int, int]{
+			{
+				Name:      "deposit",
+				Generator: gen.IntRange(1, 10),
+				Execute:   func(b, v int) (int, error) { return b + v, nil },
+			},
+			{
+				Name:      "withdraw",
+				Generator: gen.IntRange(1, 20),
+				Execute: func(b, v int) (int, error) {
+					if v > b && !bug {
+						return b, errors.New("insufficient funds")
+					}
+					return b - v, nil
+				},
+				ExpectError: func(b, v int) bool { return v > b },
+				Postcondition: func(from, v, to int) bool {
+					return to == from-v
+				},
+			},
+		},
+		Invariant: func(b int) error {
+			if b < 0 {
+				return fmt.Errorf("negative balance %d", b)
+			}
+			return nil
+		},
+	}
+}
+
+// TestExpectedErrors tests that expected errors are recorded and checked.
+func TestExpectedErrors(t *testing.T) {
+	seq := CommandSequence[int]{Commands: []int{5, 8, 3}, Names: []string{"deposit", "withdraw", "withdraw"}}
+
+	result := executeStateMachine(accountMachine(false), seq)
+	h := result.ExecutionHistory
+	if h[0].ExpectedError || !h[1].ExpectedError || h[2].ExpectedError {
+		t.Errorf("Expected only the second step to expect an error, got %v, %v, %v",
+			h[0].ExpectedError, h[1].ExpectedError, h[2].ExpectedError)
+	}
+	if h[1].Error == nil || result.FinalState != 2 {
+		t.Errorf("Expected the overdraft to fail and a final balance of 2, got %v and %d", h[1].Error, result.FinalState)
+	}
+	if i, err := checkHistory(accountMachine(false), h); err != nil {
+		t.Errorf("Expected the expected error to pass, got step %d: %v", i, err)
+	}
+
+	buggy := executeStateMachine(accountMachine(true), seq)
+	i, err := checkHistory(accountMachine(true), buggy.ExecutionHistory)
+	if i != 1 || fmt.Sprint(err) != "expected an error, got none" {
+		t.Errorf("Expected the missing error at index 1, got %d: %v", i, err)
+	}
+	if trace := describeTrace(buggy.ExecutionHistory); !strings.Contains(trace, "error:  none (expected one)") {
+		t.Errorf("Expected the trace to show the missing error, got %q", trace)
+	}
+	if trace := describeTrace(h); !strings.Contains(trace, "error:  insufficient funds (expected)") {
+		t.Errorf("Expected the trace to show the expected error, got %q", trace)
+	}
+}
+
+// TestStateMachineExpectedErrors tests a machine whose generated sequences
+// include commands that are expected to fail.
+func TestStateMachineExpectedErrors(t *testing.T) {
+	TestStateMachine(t, accountMachine(false), Config{Seed: 1, Examples: 50, MaxShrink: 100, StopOnFirstFailure: true})
+}
@@ -41,3 +41,19 @@ func Test_CPF_Invalid(t *testing.T) {
 		}
 	})
 }
+
+// Fuzz_SumBelowLimit demonstrates a property run under Go's fuzzing engine.
+// The property "the elements sum to less than 1000" is false; run it with
+// `go test -tags demo -fuzz Fuzz_SumBelowLimit ./testfailures/demo/` to see
+// the fuzzer find an input and rapidx shrink the generated slice.
+func Fuzz_SumBelowLimit(f *testing.F) {
+	prop.Fuzz(f, gen.SliceOf(gen.IntRange(0, 500), gen.Size{Max: 10}), func(t *testing.T, xs []int) {
+		sum := 0
+		for _, x := range xs {
+			sum += x
+		}
+		if sum >= 1000 {
+			t.Fatalf("sum of %v is %d", xs, sum)
+		}
+	})
+}
@@ -9,6 +9,7 @@ package framework
 import (
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/lucaskalb/rapidx/gen"
 	"github.com/lucaskalb/rapidx/prop"
@@ -115,3 +116,70 @@ func TestForAll_SequentialStopOnFirstFailureFalse(t *testing.T) {
 		t.Errorf("This should fail: got %d", val)
 	})
 }
+
+// TestForAll_DistinctFailures tests that, with StopOnFirstFailure set to
+// false, the run keeps exploring and reports each distinct failure once: the
+// panic above 900 (by its site), and the values congruent to 3 modulo 7
+// once per minimal counterexample.
+func TestForAll_DistinctFailures(t *testing.T) {
+	config := prop.Config{
+		Seed:               12345,
+		Examples:           100,
+		MaxShrink:          400,
+		ShrinkStrat:        "bfs",
+		Parallelism:        1,
+		StopOnFirstFailure: false,
+	}
+
+	prop.ForAll(t, config, gen.IntRange(0, 1000))(func(t *testing.T, val int) {
+		if val > 900 {
+			panic("value too large")
+		}
+		if val%7 == 3 {
+			t.Errorf("This should fail: got %d", val)
+		}
+	})
+}
+
+// TestForAll_ClassifyWithShrinking tests that the label distribution counts
+// only the examples of the run: the shrink candidates and the rerun of the
+// minimal counterexample are not tracked, so the table shows 10 examples.
+func TestForAll_ClassifyWithShrinking(t *testing.T) {
+	config := prop.Config{
+		Seed:               12345,
+		Examples:           10,
+		MaxShrink:          400,
+		ShrinkStrat:        "bfs",
+		Parallelism:        1,
+		StopOnFirstFailure: false,
+	}
+
+	prop.ForAll(t, config, gen.IntRange(0, 1000))(func(t *testing.T, val int) {
+		prop.Classify(t, "large", val > 500)
+		if val > 500 {
+			t.Errorf("This should fail: got %d", val)
+		}
+	})
+}
+
+// TestForAll_ReportAfterTimeout tests that a body still running when its
+// example times out can report to its abandoned T without crashing the test
+// binary: the example fails with the timeout, and the testing package only
+// logs the late report in the parent test.
+func TestForAll_ReportAfterTimeout(t *testing.T) {
+	config := prop.Config{
+		Seed:               12345,
+		Examples:           1,
+		MaxShrink:          2,
+		ShrinkStrat:        "bfs",
+		Parallelism:        1,
+		StopOnFirstFailure: true,
+		Timeout:            50 * time.Millisecond,
+	}
+
+	prop.ForAll(t, config, gen.IntRange(0, 1000))(func(t *testing.T, val int) {
+		time.Sleep(100 * time.Millisecond)
+		t.Errorf("This should not crash the test binary: got %d", val)
+	})
+	time.Sleep(200 * time.Millisecond) // let the abandoned bodies report
+}